@@ -0,0 +1,156 @@
+package controllers
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Algoritma-dan-Pemrograman-ITS/Framework-Programming-GIN-GORM/initializers"
+	"github.com/Algoritma-dan-Pemrograman-ITS/Framework-Programming-GIN-GORM/models"
+	"github.com/Algoritma-dan-Pemrograman-ITS/Framework-Programming-GIN-GORM/utils"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	apiKeyPrefix     = "dl_"
+	maxActiveAPIKeys = 20
+)
+
+type apiKeyResponse struct {
+	ID         uint       `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+func newAPIKeyResponse(key models.APIKey) apiKeyResponse {
+	return apiKeyResponse{
+		ID:         key.ID,
+		Name:       key.Name,
+		Prefix:     key.Prefix,
+		Scopes:     key.ScopeList(),
+		ExpiresAt:  key.ExpiresAt,
+		LastUsedAt: key.LastUsedAt,
+		CreatedAt:  key.CreatedAt,
+	}
+}
+
+// GetAPIKeys lists the active API keys of the logged in user (never the keys themselves)
+func GetAPIKeys(c *gin.Context) {
+	user, ok := getUserFromContext(c)
+	if !ok {
+		return
+	}
+
+	var keys []models.APIKey
+	if err := initializers.DB.Where("user_id = ? AND revoked_at IS NULL", user.ID).Order("created_at desc").Find(&keys).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not load API keys"})
+		return
+	}
+
+	response := make([]apiKeyResponse, 0, len(keys))
+	for _, key := range keys {
+		response = append(response, newAPIKeyResponse(key))
+	}
+
+	c.JSON(http.StatusOK, gin.H{"api_keys": response})
+}
+
+// CreateAPIKey issues a new key. Body: "name", optional "scopes" (read or write, plus permission names)
+// and "expires_in_days". The key is only returned in this response.
+func CreateAPIKey(c *gin.Context) {
+	var body struct {
+		Name          string   `json:"name" binding:"required,max=100"`
+		Scopes        []string `json:"scopes"`
+		ExpiresInDays int      `json:"expires_in_days" binding:"min=0,max=3650"`
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, ok := getUserFromContext(c)
+	if !ok {
+		return
+	}
+
+	for _, scope := range body.Scopes {
+		if !models.IsValidScope(scope) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown scope: " + scope})
+			return
+		}
+		if scope != models.ScopeRead && scope != models.ScopeWrite && !user.Can(scope) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Your role does not grant " + scope})
+			return
+		}
+	}
+	// Permission scopes narrow what a write or read key may do, they do not replace the method scope
+	if len(body.Scopes) > 0 && !slices.Contains(body.Scopes, models.ScopeRead) && !slices.Contains(body.Scopes, models.ScopeWrite) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Scopes must include read or write, permission names only add staff routes on top"})
+		return
+	}
+
+	var active int64
+	initializers.DB.Model(&models.APIKey{}).Where("user_id = ? AND revoked_at IS NULL", user.ID).Count(&active)
+	if active >= maxActiveAPIKeys {
+		c.JSON(http.StatusConflict, gin.H{"error": "Too many API keys, revoke one first"})
+		return
+	}
+
+	random, err := utils.RandomToken(24)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not create API key"})
+		return
+	}
+	rawKey := apiKeyPrefix + random
+
+	key := models.APIKey{
+		UserID:  user.ID,
+		Name:    strings.TrimSpace(body.Name),
+		Prefix:  rawKey[:len(apiKeyPrefix)+8],
+		KeyHash: utils.HashToken(rawKey),
+		Scopes:  strings.Join(body.Scopes, " "),
+	}
+	if body.ExpiresInDays > 0 {
+		expiresAt := time.Now().AddDate(0, 0, body.ExpiresInDays)
+		key.ExpiresAt = &expiresAt
+	}
+
+	if err := initializers.DB.Create(&key).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not create API key"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"api_key": newAPIKeyResponse(key),
+		"key":     rawKey,
+		"message": "Store this key now, it will not be shown again",
+	})
+}
+
+// RevokeAPIKey stops a key from working
+func RevokeAPIKey(c *gin.Context) {
+	user, ok := getUserFromContext(c)
+	if !ok {
+		return
+	}
+
+	result := initializers.DB.Model(&models.APIKey{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", c.Param("id"), user.ID).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not revoke API key"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "API key not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "API key revoked"})
+}
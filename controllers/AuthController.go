@@ -1,16 +1,16 @@
 package controllers
 
 import (
+	"log"
 	"net/http"
-	"os"
-	"strconv"
 	"time"
 
 	"github.com/Algoritma-dan-Pemrograman-ITS/Framework-Programming-GIN-GORM/initializers"
 	"github.com/Algoritma-dan-Pemrograman-ITS/Framework-Programming-GIN-GORM/models"
+	"github.com/Algoritma-dan-Pemrograman-ITS/Framework-Programming-GIN-GORM/utils"
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v4"
 	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
 )
 
 func Register(c *gin.Context) {
@@ -38,17 +38,24 @@ func Register(c *gin.Context) {
 		return
 	}
 
+	// The account is usable right away, the verification link only unlocks what the policy restricts
+	if err := sendVerificationEmail(user); err != nil {
+		log.Printf("Could not send verification email to user %d: %v", user.ID, err)
+	}
+
 	// Create a response struct without password
 	userResponse := struct {
-		ID    uint   `json:"id"`
-		Name  string `json:"name"`
-		Email string `json:"email"`
-		Role  string `json:"role"`
+		ID            uint   `json:"id"`
+		Name          string `json:"name"`
+		Email         string `json:"email"`
+		Role          string `json:"role"`
+		EmailVerified bool   `json:"email_verified"`
 	}{
-		ID:    user.ID,
-		Name:  user.Name,
-		Email: user.Email,
-		Role:  user.Role,
+		ID:            user.ID,
+		Name:          user.Name,
+		Email:         user.Email,
+		Role:          user.Role,
+		EmailVerified: user.EmailVerified,
 	}
 
 	c.JSON(http.StatusOK, gin.H{"user": userResponse})
@@ -65,53 +72,260 @@ func Login(c *gin.Context) {
 		return
 	}
 
+	if !checkLoginThrottle(c, body.Email) {
+		return
+	}
+
 	var user models.User
 	if result := initializers.DB.First(&user, "email = ?", body.Email); result.Error != nil {
+		recordLoginFailure(c, body.Email)
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
 		return
 	}
 
 	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(body.Password)); err != nil {
+		recordLoginFailure(c, body.Email)
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
 		return
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"sub": strconv.FormatUint(uint64(user.ID), 10),
-		"exp": time.Now().Add(24 * time.Hour).Unix(), // Changed to 24 hours
-	})
+	if user.IsSuspended() {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Account suspended"})
+		return
+	}
 
-	secret := os.Getenv("JWT_SECRET")
+	if !user.EmailVerified && utils.EmailVerificationPolicy() == utils.EmailVerificationLogin {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Please verify your email address first"})
+		return
+	}
+
+	// With 2FA on, the password only earns a short-lived "mfa pending" token for /login/2fa
+	if user.TOTPEnabled {
+		mfaToken, err := utils.GenerateSignedToken(mfaPendingPurpose, user.ID, nil, mfaPendingTTL)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not create token"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"mfa_required": true,
+			"mfa_token":    mfaToken,
+			"expires_in":   int(mfaPendingTTL.Seconds()),
+		})
+		return
+	}
 
-	tokenString, err := token.SignedString([]byte(secret))
+	completeLogin(c, user)
+}
+
+// completeLogin starts a new session for an authenticated user and writes the Login response
+func completeLogin(c *gin.Context, user models.User) {
+	if user.IsSuspended() {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Account suspended"})
+		return
+	}
+
+	tokens, err := startSession(c, user)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not create token"})
 		return
 	}
 
-	maxAge := 24 * 60 * 60 // 24 hours in seconds
-	secure := os.Getenv("ENV") == "production"
-	c.SetCookie("token", tokenString, maxAge, "/", "", secure, true)
-
 	// Create a response struct without password
 	userResponse := struct {
-		ID    uint   `json:"id"`
-		Name  string `json:"name"`
-		Email string `json:"email"`
-		Role  string `json:"role"`
+		ID               uint     `json:"id"`
+		Name             string   `json:"name"`
+		Email            string   `json:"email"`
+		Role             string   `json:"role"`
+		Permissions      []string `json:"permissions"`
+		EmailVerified    bool     `json:"email_verified"`
+		TwoFactorEnabled bool     `json:"two_factor_enabled"`
 	}{
-		ID:    user.ID,
-		Name:  user.Name,
-		Email: user.Email,
-		Role:  user.Role,
+		ID:               user.ID,
+		Name:             user.Name,
+		Email:            user.Email,
+		Role:             user.Role,
+		Permissions:      user.Permissions(),
+		EmailVerified:    user.EmailVerified,
+		TwoFactorEnabled: user.TOTPEnabled,
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"token":         tokens.AccessToken,
+		"refresh_token": tokens.RefreshToken,
+		"csrf_token":    tokens.CSRFToken,
+		"expires_in":    tokens.ExpiresIn,
+		"user":          userResponse,
+	})
+}
+
+// startSession records the successful login and issues tokens (and cookies) in a new refresh token family
+func startSession(c *gin.Context, user models.User) (*issuedTokens, error) {
+	recordLoginSuccess(user.Email)
+
+	// Every login starts a new refresh token family, tracked as a session
+	familyID, err := utils.RandomToken(16)
+	if err != nil {
+		return nil, err
+	}
+
+	userAgent := c.Request.UserAgent()
+	if len(userAgent) > 512 {
+		userAgent = userAgent[:512]
+	}
+	session := models.Session{
+		UserID:     user.ID,
+		FamilyID:   familyID,
+		UserAgent:  userAgent,
+		IP:         c.ClientIP(),
+		LastSeenAt: time.Now(),
+	}
+	if err := initializers.DB.Create(&session).Error; err != nil {
+		return nil, err
+	}
+
+	return issueTokens(c, user.ID, familyID)
+}
+
+// Refresh exchanges a refresh token (cookie "refresh_token" or JSON body) for a new access/refresh pair.
+// The presented token is rotated; presenting an already-rotated token revokes the whole family.
+func Refresh(c *gin.Context) {
+	rawToken, fromCookie := refreshTokenFromRequest(c)
+	if rawToken == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Refresh token required"})
+		return
+	}
+	if fromCookie && !utils.ValidCSRF(c) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Missing or invalid CSRF token"})
+		return
+	}
+
+	var stored models.RefreshToken
+	if result := initializers.DB.First(&stored, "token_hash = ?", utils.HashToken(rawToken)); result.Error != nil {
+		clearAuthCookies(c)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid refresh token"})
+		return
+	}
+
+	if stored.RevokedAt != nil {
+		clearAuthCookies(c)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Refresh token revoked"})
+		return
+	}
+
+	// Reuse of a rotated token means it was probably stolen, kill the whole family
+	if stored.UsedAt != nil {
+		revokeFamily(stored.FamilyID)
+		clearAuthCookies(c)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Refresh token reuse detected, please login again"})
+		return
+	}
+
+	if time.Now().After(stored.ExpiresAt) {
+		clearAuthCookies(c)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Refresh token expired"})
+		return
+	}
+
+	var user models.User
+	if result := initializers.DB.First(&user, stored.UserID); result.Error != nil {
+		clearAuthCookies(c)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	if user.IsSuspended() {
+		clearAuthCookies(c)
+		c.JSON(http.StatusForbidden, gin.H{"error": "Account suspended"})
+		return
+	}
+
+	// Mark as used only if nobody else did it first (two concurrent refreshes with the same token)
+	result := initializers.DB.Model(&models.RefreshToken{}).
+		Where("id = ? AND used_at IS NULL", stored.ID).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not rotate refresh token"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		revokeFamily(stored.FamilyID)
+		clearAuthCookies(c)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Refresh token reuse detected, please login again"})
+		return
+	}
+
+	tokens, err := issueTokens(c, user.ID, stored.FamilyID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not create token"})
+		return
 	}
 
+	initializers.DB.Model(&models.Session{}).Where("family_id = ?", stored.FamilyID).
+		Updates(map[string]interface{}{"last_seen_at": time.Now(), "ip": c.ClientIP()})
+
 	c.JSON(http.StatusOK, gin.H{
-		"token": tokenString,
-		"user":  userResponse,
+		"token":         tokens.AccessToken,
+		"refresh_token": tokens.RefreshToken,
+		"csrf_token":    tokens.CSRFToken,
+		"expires_in":    tokens.ExpiresIn,
 	})
 }
 
+// Logout revokes the refresh token family of the current session and clears the cookies.
+// It works without a valid access token so an expired session can still be ended.
+func Logout(c *gin.Context) {
+	familyID := ""
+
+	rawToken, refreshFromCookie := refreshTokenFromRequest(c)
+	tokenString, accessFromCookie := accessTokenFromRequest(c)
+	if (refreshFromCookie || accessFromCookie) && !utils.ValidCSRF(c) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Missing or invalid CSRF token"})
+		return
+	}
+
+	if rawToken != "" {
+		var stored models.RefreshToken
+		if result := initializers.DB.First(&stored, "token_hash = ?", utils.HashToken(rawToken)); result.Error == nil {
+			familyID = stored.FamilyID
+		}
+	}
+
+	// Fall back to the family carried by the access token
+	if familyID == "" {
+		if tokenString != "" {
+			if claims, err := utils.ParseAccessToken(tokenString); err == nil {
+				familyID = claims.FamilyID
+			}
+		}
+	}
+
+	if familyID != "" {
+		if err := revokeFamily(familyID); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not revoke session"})
+			return
+		}
+	}
+
+	clearAuthCookies(c)
+	c.JSON(http.StatusOK, gin.H{"message": "Logged out successfully"})
+}
+
+// LogoutAll revokes every refresh token family of the current user (all devices)
+func LogoutAll(c *gin.Context) {
+	user, ok := getUserFromContext(c)
+	if !ok {
+		return
+	}
+
+	if err := revokeAllFamilies(initializers.DB, user.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not revoke sessions"})
+		return
+	}
+
+	clearAuthCookies(c)
+	c.JSON(http.StatusOK, gin.H{"message": "Logged out from all devices"})
+}
+
 func Profile(c *gin.Context) {
 	u, exists := c.Get("user")
 	if !exists {
@@ -127,15 +341,25 @@ func Profile(c *gin.Context) {
 
 	// Create a response struct without password
 	userResponse := struct {
-		ID    uint   `json:"id"`
-		Name  string `json:"name"`
-		Email string `json:"email"`
-		Role  string `json:"role"`
+		ID               uint     `json:"id"`
+		Name             string   `json:"name"`
+		Email            string   `json:"email"`
+		Role             string   `json:"role"`
+		Permissions      []string `json:"permissions"`
+		Bio              string   `json:"bio"`
+		AvatarURL        string   `json:"avatar_url"`
+		EmailVerified    bool     `json:"email_verified"`
+		TwoFactorEnabled bool     `json:"two_factor_enabled"`
 	}{
-		ID:    user.ID,
-		Name:  user.Name,
-		Email: user.Email,
-		Role:  user.Role,
+		ID:               user.ID,
+		Name:             user.Name,
+		Email:            user.Email,
+		Role:             user.Role,
+		Permissions:      user.Permissions(),
+		Bio:              user.Bio,
+		AvatarURL:        user.AvatarURL,
+		EmailVerified:    user.EmailVerified,
+		TwoFactorEnabled: user.TOTPEnabled,
 	}
 
 	c.JSON(http.StatusOK, gin.H{"user": userResponse})
@@ -164,3 +388,110 @@ func DeleteUser(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"message": "User deleted successfully"})
 }
+
+type issuedTokens struct {
+	AccessToken  string
+	RefreshToken string
+	CSRFToken    string
+	ExpiresIn    int
+}
+
+// issueTokens stores a new refresh token in the given family, signs an access token and sets both cookies
+func issueTokens(c *gin.Context, userID uint, familyID string) (*issuedTokens, error) {
+	rawRefresh, err := utils.RandomToken(32)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshTTL := utils.RefreshTokenTTL()
+	refresh := models.RefreshToken{
+		UserID:    userID,
+		FamilyID:  familyID,
+		TokenHash: utils.HashToken(rawRefresh),
+		ExpiresAt: time.Now().Add(refreshTTL),
+	}
+	if result := initializers.DB.Create(&refresh); result.Error != nil {
+		return nil, result.Error
+	}
+
+	accessToken, err := utils.GenerateAccessToken(userID, familyID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Readable by the frontend (not HttpOnly), echoed back in X-CSRF-Token when using the cookies
+	csrfToken, err := utils.RandomToken(32)
+	if err != nil {
+		return nil, err
+	}
+
+	accessTTL := int(utils.AccessTokenTTL().Seconds())
+	utils.SetCookie(c, "token", accessToken, accessTTL, "/", true)
+	utils.SetCookie(c, "refresh_token", rawRefresh, int(refreshTTL.Seconds()), "/", true)
+	utils.SetCookie(c, utils.CSRFCookie, csrfToken, int(refreshTTL.Seconds()), "/", false)
+
+	return &issuedTokens{AccessToken: accessToken, RefreshToken: rawRefresh, CSRFToken: csrfToken, ExpiresIn: accessTTL}, nil
+}
+
+func clearAuthCookies(c *gin.Context) {
+	utils.SetCookie(c, "token", "", -1, "/", true)
+	utils.SetCookie(c, "refresh_token", "", -1, "/", true)
+	utils.SetCookie(c, utils.CSRFCookie, "", -1, "/", false)
+}
+
+// refreshTokenFromRequest reads the refresh token from {"refresh_token": "..."}, falling back to the cookie.
+// fromCookie tells the caller the request needs a CSRF token.
+func refreshTokenFromRequest(c *gin.Context) (token string, fromCookie bool) {
+	var body struct {
+		RefreshToken string `json:"refresh_token"`
+	}
+	if err := c.ShouldBindJSON(&body); err == nil && body.RefreshToken != "" {
+		return body.RefreshToken, false
+	}
+
+	if token, err := c.Cookie("refresh_token"); err == nil && token != "" {
+		return token, true
+	}
+	return "", false
+}
+
+// accessTokenFromRequest prefers the Authorization header over the "token" cookie, like AuthMiddleware
+func accessTokenFromRequest(c *gin.Context) (token string, fromCookie bool) {
+	authHeader := c.GetHeader("Authorization")
+	if len(authHeader) > 7 && authHeader[:7] == "Bearer " {
+		return authHeader[7:], false
+	}
+	if token, err := c.Cookie("token"); err == nil && token != "" {
+		return token, true
+	}
+	return "", false
+}
+
+// The revoke helpers mark both the refresh tokens and the session of a family,
+// AuthMiddleware checks the session so access tokens stop working right away too.
+
+func revokeFamily(familyID string) error {
+	return revokeWhere(initializers.DB, "family_id = ?", familyID)
+}
+
+func revokeAllFamilies(tx *gorm.DB, userID uint) error {
+	return revokeWhere(tx, "user_id = ?", userID)
+}
+
+// revokeOtherFamilies logs out every session of the user except keepFamilyID
+func revokeOtherFamilies(tx *gorm.DB, userID uint, keepFamilyID string) error {
+	if keepFamilyID == "" {
+		return revokeAllFamilies(tx, userID)
+	}
+	return revokeWhere(tx, "user_id = ? AND family_id <> ?", userID, keepFamilyID)
+}
+
+func revokeWhere(tx *gorm.DB, condition string, args ...interface{}) error {
+	now := time.Now()
+	if err := tx.Model(&models.RefreshToken{}).Where(condition, args...).Where("revoked_at IS NULL").
+		Update("revoked_at", now).Error; err != nil {
+		return err
+	}
+	return tx.Model(&models.Session{}).Where(condition, args...).Where("revoked_at IS NULL").
+		Update("revoked_at", now).Error
+}
@@ -0,0 +1,144 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Algoritma-dan-Pemrograman-ITS/Framework-Programming-GIN-GORM/initializers"
+	"github.com/Algoritma-dan-Pemrograman-ITS/Framework-Programming-GIN-GORM/models"
+	"github.com/Algoritma-dan-Pemrograman-ITS/Framework-Programming-GIN-GORM/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// loginCookies starts a session for a new user and returns the cookies a browser would keep
+func loginCookies(t *testing.T) (models.User, []*http.Cookie) {
+	t.Helper()
+	user := models.User{Name: "Alice", Email: "alice@example.com"}
+	if err := initializers.DB.Create(&user).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/login", nil)
+	if _, err := startSession(c, user); err != nil {
+		t.Fatal(err)
+	}
+	return user, w.Result().Cookies()
+}
+
+func cookieValue(cookies []*http.Cookie, name string) string {
+	for _, cookie := range cookies {
+		if cookie.Name == name {
+			return cookie.Value
+		}
+	}
+	return ""
+}
+
+func activeSessions(t *testing.T, userID uint) int64 {
+	t.Helper()
+	var count int64
+	initializers.DB.Model(&models.Session{}).Where("user_id = ? AND revoked_at IS NULL", userID).Count(&count)
+	return count
+}
+
+func TestLogoutWithCookiesNeedsCSRFHeader(t *testing.T) {
+	setupTestDB(t)
+	t.Setenv("JWT_SECRET", "test-secret")
+	user, cookies := loginCookies(t)
+
+	logout := func(csrf string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodPost, "/logout", nil)
+		for _, cookie := range cookies {
+			c.Request.AddCookie(cookie)
+		}
+		if csrf != "" {
+			c.Request.Header.Set(utils.CSRFHeader, csrf)
+		}
+		Logout(c)
+		return w
+	}
+
+	if w := logout(""); w.Code != http.StatusForbidden {
+		t.Fatalf("logout without CSRF header: status %d, want 403", w.Code)
+	}
+	if activeSessions(t, user.ID) != 1 {
+		t.Fatal("session revoked by a request without CSRF header")
+	}
+
+	w := logout(cookieValue(cookies, utils.CSRFCookie))
+	if w.Code != http.StatusOK {
+		t.Fatalf("logout with CSRF header: status %d, want 200", w.Code)
+	}
+	if activeSessions(t, user.ID) != 0 {
+		t.Error("session still active after logout")
+	}
+	for _, name := range []string{"token", "refresh_token"} {
+		cleared := false
+		for _, cookie := range w.Result().Cookies() {
+			if cookie.Name == name && cookie.MaxAge < 0 {
+				cleared = true
+			}
+		}
+		if !cleared {
+			t.Errorf("logout did not clear the %s cookie", name)
+		}
+	}
+}
+
+func refresh(rawToken string) (int, string) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/auth/refresh", strings.NewReader(`{"refresh_token":"`+rawToken+`"}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+	Refresh(c)
+
+	var body struct {
+		RefreshToken string `json:"refresh_token"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &body)
+	return w.Code, body.RefreshToken
+}
+
+func TestRefreshRotatesAndDetectsReuse(t *testing.T) {
+	setupTestDB(t)
+	t.Setenv("JWT_SECRET", "test-secret")
+	user, cookies := loginCookies(t)
+	first := cookieValue(cookies, "refresh_token")
+
+	code, second := refresh(first)
+	if code != http.StatusOK || second == "" || second == first {
+		t.Fatalf("refresh: status %d, new token %q, want 200 and a rotated token", code, second)
+	}
+
+	// Presenting the rotated token again revokes the family, including the token it was exchanged for
+	if code, _ := refresh(first); code != http.StatusUnauthorized {
+		t.Errorf("reused refresh token: status %d, want 401", code)
+	}
+	if code, _ := refresh(second); code != http.StatusUnauthorized {
+		t.Errorf("refresh token of a revoked family: status %d, want 401", code)
+	}
+	if activeSessions(t, user.ID) != 0 {
+		t.Error("session still active after refresh token reuse")
+	}
+}
+
+func TestRefreshRejectsExpiredToken(t *testing.T) {
+	setupTestDB(t)
+	t.Setenv("JWT_SECRET", "test-secret")
+	_, cookies := loginCookies(t)
+	raw := cookieValue(cookies, "refresh_token")
+
+	initializers.DB.Model(&models.RefreshToken{}).Where("token_hash = ?", utils.HashToken(raw)).
+		Update("expires_at", time.Now().Add(-time.Minute))
+	if code, _ := refresh(raw); code != http.StatusUnauthorized {
+		t.Errorf("expired refresh token: status %d, want 401", code)
+	}
+}
@@ -92,5 +92,25 @@ func GetBookmarkedNovels(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"bookmarked_novels": user.BookmarkedNovels})
+	// Attach the reading progress of each bookmarked novel (for the "continue reading" shelf)
+	var progress []models.ReadingProgress
+	if err := initializers.DB.Where("user_id = ?", user.ID).Find(&progress).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not fetch reading progress"})
+		return
+	}
+	progressByNovel := make(map[uint]*models.ReadingProgress, len(progress))
+	for i := range progress {
+		progressByNovel[progress[i].NovelID] = &progress[i]
+	}
+
+	type bookmarkedNovel struct {
+		*models.Novel
+		Progress *models.ReadingProgress `json:"progress"`
+	}
+	bookmarks := make([]bookmarkedNovel, 0, len(user.BookmarkedNovels))
+	for _, novel := range user.BookmarkedNovels {
+		bookmarks = append(bookmarks, bookmarkedNovel{Novel: novel, Progress: progressByNovel[novel.ID]})
+	}
+
+	c.JSON(http.StatusOK, gin.H{"bookmarked_novels": bookmarks})
 }
@@ -0,0 +1,212 @@
+package controllers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Algoritma-dan-Pemrograman-ITS/Framework-Programming-GIN-GORM/initializers"
+	"github.com/Algoritma-dan-Pemrograman-ITS/Framework-Programming-GIN-GORM/models"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// publishedChapters limits a chapter query to what readers are allowed to see
+func publishedChapters(tx *gorm.DB) *gorm.DB {
+	return tx.Where("published_at IS NOT NULL AND published_at <= ?", time.Now())
+}
+
+func countWords(body string) int {
+	return len(strings.Fields(body))
+}
+
+// GetChapters is the public table of contents of a novel (published chapters, without the body)
+func GetChapters(c *gin.Context) {
+	novelID := c.Param("id")
+
+	var novel models.Novel
+	if result := initializers.DB.First(&novel, novelID); result.Error != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Novel not found"})
+		return
+	}
+
+	var chapters []models.Chapter
+	err := publishedChapters(initializers.DB).
+		Select("id, novel_id, number, title, word_count, published_at, created_at, updated_at").
+		Where("novel_id = ?", novel.ID).
+		Order("number").
+		Find(&chapters).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"novel_id": novel.ID, "chapters": chapters})
+}
+
+// GetChapter returns one published chapter with the numbers of the previous and next published chapters
+func GetChapter(c *gin.Context) {
+	novelID := c.Param("id")
+	number := c.Param("number")
+
+	// Also hides the chapters of a novel in the trash
+	var novel models.Novel
+	if result := initializers.DB.First(&novel, novelID); result.Error != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Novel not found"})
+		return
+	}
+
+	var chapter models.Chapter
+	if result := publishedChapters(initializers.DB).Where("novel_id = ? AND number = ?", novel.ID, number).First(&chapter); result.Error != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Chapter not found"})
+		return
+	}
+
+	// Numbers may have gaps (deleted or unpublished chapters), so look for the closest neighbours
+	var prev, next *int
+	var neighbour models.Chapter
+	if publishedChapters(initializers.DB).Where("novel_id = ? AND number < ?", chapter.NovelID, chapter.Number).
+		Order("number DESC").Select("number").Take(&neighbour).Error == nil {
+		n := neighbour.Number
+		prev = &n
+	}
+	neighbour = models.Chapter{}
+	if publishedChapters(initializers.DB).Where("novel_id = ? AND number > ?", chapter.NovelID, chapter.Number).
+		Order("number ASC").Select("number").Take(&neighbour).Error == nil {
+		n := neighbour.Number
+		next = &n
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"chapter": chapter,
+		"prev":    prev,
+		"next":    next,
+	})
+}
+
+// Use Raw JSON POST with param: "number", "title", "body", optional "published_at" (RFC3339) or "draft": true
+func CreateChapter(c *gin.Context) {
+	var body struct {
+		Number      int        `json:"number" binding:"required,min=1"`
+		Title       string     `json:"title" binding:"required,max=255"`
+		Body        string     `json:"body" binding:"required"`
+		PublishedAt *time.Time `json:"published_at"`
+		Draft       bool       `json:"draft"`
+	}
+
+	if err := c.BindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var novel models.Novel
+	if result := initializers.DB.First(&novel, c.Param("id")); result.Error != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Novel not found"})
+		return
+	}
+
+	var existing int64
+	initializers.DB.Model(&models.Chapter{}).Where("novel_id = ? AND number = ?", novel.ID, body.Number).Count(&existing)
+	if existing > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Chapter number already exists for this novel"})
+		return
+	}
+
+	// Publish immediately unless a date or draft is given
+	publishedAt := body.PublishedAt
+	if publishedAt == nil && !body.Draft {
+		now := time.Now()
+		publishedAt = &now
+	}
+	if body.Draft {
+		publishedAt = nil
+	}
+
+	chapter := models.Chapter{
+		NovelID:     novel.ID,
+		Number:      body.Number,
+		Title:       body.Title,
+		Body:        body.Body,
+		WordCount:   countWords(body.Body),
+		PublishedAt: publishedAt,
+	}
+
+	if result := initializers.DB.Create(&chapter); result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": result.Error.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"chapter": chapter})
+}
+
+func UpdateChapter(c *gin.Context) {
+	var body struct {
+		Number      *int       `json:"number" binding:"omitempty,min=1"`
+		Title       string     `json:"title" binding:"max=255"`
+		Body        *string    `json:"body"`
+		PublishedAt *time.Time `json:"published_at"`
+		Draft       *bool      `json:"draft"` // true unpublishes, false publishes now (if no published_at)
+	}
+
+	if err := c.BindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var chapter models.Chapter
+	if result := initializers.DB.Where("novel_id = ? AND number = ?", c.Param("id"), c.Param("number")).First(&chapter); result.Error != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Chapter not found"})
+		return
+	}
+
+	updates := make(map[string]interface{})
+
+	if body.Number != nil && *body.Number != chapter.Number {
+		var existing int64
+		initializers.DB.Model(&models.Chapter{}).Where("novel_id = ? AND number = ?", chapter.NovelID, *body.Number).Count(&existing)
+		if existing > 0 {
+			c.JSON(http.StatusConflict, gin.H{"error": "Chapter number already exists for this novel"})
+			return
+		}
+		updates["number"] = *body.Number
+	}
+	if body.Title != "" {
+		updates["title"] = body.Title
+	}
+	if body.Body != nil {
+		updates["body"] = *body.Body
+		updates["word_count"] = countWords(*body.Body)
+	}
+	if body.PublishedAt != nil {
+		updates["published_at"] = *body.PublishedAt
+	}
+	if body.Draft != nil {
+		if *body.Draft {
+			updates["published_at"] = nil
+		} else if body.PublishedAt == nil && chapter.PublishedAt == nil {
+			updates["published_at"] = time.Now()
+		}
+	}
+
+	if result := initializers.DB.Model(&chapter).Updates(updates); result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": result.Error.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"chapter": chapter})
+}
+
+func DeleteChapter(c *gin.Context) {
+	var chapter models.Chapter
+	if result := initializers.DB.Where("novel_id = ? AND number = ?", c.Param("id"), c.Param("number")).First(&chapter); result.Error != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Chapter not found"})
+		return
+	}
+
+	if result := initializers.DB.Delete(&chapter); result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": result.Error.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Chapter deleted successfully"})
+}
@@ -0,0 +1,104 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/Algoritma-dan-Pemrograman-ITS/Framework-Programming-GIN-GORM/initializers"
+	"github.com/Algoritma-dan-Pemrograman-ITS/Framework-Programming-GIN-GORM/models"
+	"github.com/gin-gonic/gin"
+)
+
+// novelWithChapters stores chapters 1, 3 and 6 published, 2 as a draft and 5 scheduled for tomorrow
+func novelWithChapters(t *testing.T) models.Novel {
+	t.Helper()
+	novel := models.Novel{Title: "Dune", Author: "Frank Herbert"}
+	if err := initializers.DB.Create(&novel).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	past, future := time.Now().Add(-time.Hour), time.Now().Add(24*time.Hour)
+	for number, publishedAt := range map[int]*time.Time{1: &past, 2: nil, 3: &past, 5: &future, 6: &past} {
+		chapter := models.Chapter{NovelID: novel.ID, Number: number, Title: "Chapter " + strconv.Itoa(number), Body: "Text", PublishedAt: publishedAt}
+		if err := initializers.DB.Create(&chapter).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	return novel
+}
+
+func getChapter(novel models.Novel, number int) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+	c.Params = gin.Params{{Key: "id", Value: strconv.Itoa(int(novel.ID))}, {Key: "number", Value: strconv.Itoa(number)}}
+	GetChapter(c)
+	return w
+}
+
+func TestGetChapterPrevNextSkipHiddenChapters(t *testing.T) {
+	setupTestDB(t)
+	novel := novelWithChapters(t)
+
+	one, three, six := 1, 3, 6
+	cases := []struct {
+		number     int
+		prev, next *int
+	}{
+		{1, nil, &three},
+		{3, &one, &six},
+		{6, &three, nil},
+	}
+	for _, tc := range cases {
+		w := getChapter(novel, tc.number)
+		if w.Code != http.StatusOK {
+			t.Fatalf("chapter %d: status %d", tc.number, w.Code)
+		}
+		var response struct {
+			Prev *int `json:"prev"`
+			Next *int `json:"next"`
+		}
+		json.Unmarshal(w.Body.Bytes(), &response)
+		if !reflect.DeepEqual(response.Prev, tc.prev) || !reflect.DeepEqual(response.Next, tc.next) {
+			t.Errorf("chapter %d: got prev %v next %v, want %v %v", tc.number, response.Prev, response.Next, tc.prev, tc.next)
+		}
+	}
+
+	// Drafts and scheduled chapters are not readable yet
+	for _, number := range []int{2, 5, 4} {
+		if w := getChapter(novel, number); w.Code != http.StatusNotFound {
+			t.Errorf("chapter %d: status %d, want 404", number, w.Code)
+		}
+	}
+}
+
+func TestGetChaptersListsPublishedWithoutBody(t *testing.T) {
+	setupTestDB(t)
+	novel := novelWithChapters(t)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+	c.Params = gin.Params{{Key: "id", Value: strconv.Itoa(int(novel.ID))}}
+	GetChapters(c)
+
+	var response struct {
+		Chapters []models.Chapter `json:"chapters"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	var numbers []int
+	for _, chapter := range response.Chapters {
+		numbers = append(numbers, chapter.Number)
+		if chapter.Body != "" {
+			t.Errorf("chapter %d listed with its body", chapter.Number)
+		}
+	}
+	if !reflect.DeepEqual(numbers, []int{1, 3, 6}) {
+		t.Errorf("got chapters %v, want [1 3 6]", numbers)
+	}
+}
@@ -0,0 +1,346 @@
+package controllers
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Algoritma-dan-Pemrograman-ITS/Framework-Programming-GIN-GORM/initializers"
+	"github.com/Algoritma-dan-Pemrograman-ITS/Framework-Programming-GIN-GORM/models"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const exportBatchSize = 500
+
+// ExportTypes and ExportFormats list what WriteExport accepts
+var (
+	ExportTypes   = []string{"novels", "reviews"}
+	ExportFormats = []string{"csv", "json", "ndjson"}
+)
+
+var exportContentTypes = map[string]string{
+	"csv":    "text/csv; charset=utf-8",
+	"json":   "application/json",
+	"ndjson": "application/x-ndjson",
+}
+
+// exportNovel is one row of the novels export. Genres (slugs) and tags use the same ";" lists as the import.
+type exportNovel struct {
+	ID            uint     `json:"id"`
+	Title         string   `json:"title"`
+	Author        string   `json:"author"`
+	Language      string   `json:"language"`
+	YearPublished int      `json:"year_published"`
+	Rating        float64  `json:"rating"`
+	AverageRating float64  `json:"average_rating"`
+	ReviewCount   int      `json:"review_count"`
+	BookmarkCount int64    `json:"bookmark_count"`
+	Genres        []string `json:"genres"`
+	Tags          []string `json:"tags"`
+}
+
+var exportNovelHeader = []string{
+	"id", "title", "author", "language", "year_published", "rating",
+	"average_rating", "review_count", "bookmark_count", "genres", "tags",
+}
+
+func (n exportNovel) csvRecord() []string {
+	return []string{
+		strconv.FormatUint(uint64(n.ID), 10), n.Title, n.Author, n.Language, strconv.Itoa(n.YearPublished),
+		formatFloat(n.Rating), formatFloat(n.AverageRating), strconv.Itoa(n.ReviewCount),
+		strconv.FormatInt(n.BookmarkCount, 10), strings.Join(n.Genres, ";"), strings.Join(n.Tags, ";"),
+	}
+}
+
+type exportReview struct {
+	ID         uint      `json:"id"`
+	NovelID    uint      `json:"novel_id"`
+	NovelTitle string    `json:"novel_title"`
+	UserID     uint      `json:"user_id"`
+	Rating     float64   `json:"rating"`
+	Comment    string    `json:"comment"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+var exportReviewHeader = []string{"id", "novel_id", "novel_title", "user_id", "rating", "comment", "created_at", "updated_at"}
+
+func (r exportReview) csvRecord() []string {
+	return []string{
+		strconv.FormatUint(uint64(r.ID), 10), strconv.FormatUint(uint64(r.NovelID), 10), r.NovelTitle,
+		strconv.FormatUint(uint64(r.UserID), 10), formatFloat(r.Rating), r.Comment,
+		r.CreatedAt.UTC().Format(time.RFC3339), r.UpdatedAt.UTC().Format(time.RFC3339),
+	}
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// ExportCatalog streams novels (with bookmark counts) or reviews as CSV, JSON or NDJSON.
+// Accepts the filters of GET /novels; reviews are limited to the matching novels.
+// Example: localhost:8001/admin/export?type=novels&format=csv&language=english&genre=fantasy
+func ExportCatalog(c *gin.Context) {
+	kind := c.DefaultQuery("type", "novels")
+	format := c.DefaultQuery("format", "csv")
+	if !slices.Contains(ExportTypes, kind) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid type, allowed: " + strings.Join(ExportTypes, ", ")})
+		return
+	}
+	if !slices.Contains(ExportFormats, format) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid format, allowed: " + strings.Join(ExportFormats, ", ")})
+		return
+	}
+
+	filename := kind + "-" + time.Now().Format("20060102-150405") + "." + format
+	c.Header("Content-Type", exportContentTypes[format])
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	c.Status(http.StatusOK)
+
+	// The status is already sent, an error halfway can only cut the file short
+	if err := WriteExport(c.Writer, kind, format, c.Request.URL.Query()); err != nil {
+		log.Printf("export %s: %v", kind, err)
+	}
+}
+
+// WriteExport writes every matching record to w, a batch at a time so memory stays flat on large catalogs.
+// Used by ExportCatalog and the export CLI.
+func WriteExport(w io.Writer, kind, format string, filters url.Values) error {
+	var out recordWriter
+	switch format {
+	case "csv":
+		header := exportNovelHeader
+		if kind == "reviews" {
+			header = exportReviewHeader
+		}
+		out = newCSVRecordWriter(w, header)
+	case "json":
+		out = newJSONRecordWriter(w, true)
+	case "ndjson":
+		out = newJSONRecordWriter(w, false)
+	default:
+		return errors.New("unknown export format " + format)
+	}
+
+	var err error
+	switch kind {
+	case "novels":
+		err = exportNovels(out, filters)
+	case "reviews":
+		err = exportReviews(out, filters)
+	default:
+		err = errors.New("unknown export type " + kind)
+	}
+	if err != nil {
+		return err
+	}
+	return out.close()
+}
+
+func exportNovels(out recordWriter, filters url.Values) error {
+	query := filterNovels(initializers.DB.Model(&models.Novel{}), filters).Preload("Genres").Preload("Tags")
+
+	var batch []models.Novel
+	return query.FindInBatches(&batch, exportBatchSize, func(tx *gorm.DB, _ int) error {
+		ids := make([]uint, len(batch))
+		for i, novel := range batch {
+			ids[i] = novel.ID
+		}
+		var counts []struct {
+			NovelID uint
+			Count   int64
+		}
+		if err := initializers.DB.Table("user_bookmarks").Select("novel_id, COUNT(*) AS count").
+			Where("novel_id IN ?", ids).Group("novel_id").Scan(&counts).Error; err != nil {
+			return err
+		}
+		bookmarks := make(map[uint]int64, len(counts))
+		for _, count := range counts {
+			bookmarks[count.NovelID] = count.Count
+		}
+
+		for _, novel := range batch {
+			row := exportNovel{
+				ID:            novel.ID,
+				Title:         novel.Title,
+				Author:        novel.Author,
+				Language:      novel.Language,
+				YearPublished: novel.YearPublished,
+				Rating:        novel.Rating,
+				AverageRating: novel.AverageRating,
+				ReviewCount:   novel.ReviewCount,
+				BookmarkCount: bookmarks[novel.ID],
+				Genres:        []string{},
+				Tags:          []string{},
+			}
+			for _, genre := range novel.Genres {
+				row.Genres = append(row.Genres, genre.Slug)
+			}
+			for _, tag := range novel.Tags {
+				row.Tags = append(row.Tags, tag.Name)
+			}
+			if err := out.write(row); err != nil {
+				return err
+			}
+		}
+		return out.flush()
+	}).Error
+}
+
+func exportReviews(out recordWriter, filters url.Values) error {
+	novelIDs := filterNovels(initializers.DB.Model(&models.Novel{}), filters).Select("novels.id")
+
+	rows, err := initializers.DB.Model(&models.Review{}).
+		Select("reviews.id, reviews.novel_id, novels.title AS novel_title, reviews.user_id, reviews.rating, reviews.comment, reviews.created_at, reviews.updated_at").
+		Joins("JOIN novels ON novels.id = reviews.novel_id").
+		Where("reviews.novel_id IN (?)", novelIDs).
+		Order("reviews.id").
+		Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	count := 0
+	for rows.Next() {
+		var review exportReview
+		if err := initializers.DB.ScanRows(rows, &review); err != nil {
+			return err
+		}
+		if err := out.write(review); err != nil {
+			return err
+		}
+		if count++; count%exportBatchSize == 0 {
+			if err := out.flush(); err != nil {
+				return err
+			}
+		}
+	}
+	return rows.Err()
+}
+
+// exportRecord is a row of an export, marshalled as JSON or flattened for CSV
+type exportRecord interface {
+	csvRecord() []string
+}
+
+type recordWriter interface {
+	write(record exportRecord) error
+	flush() error
+	close() error
+}
+
+type csvRecordWriter struct {
+	w      io.Writer
+	csv    *csv.Writer
+	header []string
+	wrote  bool
+}
+
+func newCSVRecordWriter(w io.Writer, header []string) *csvRecordWriter {
+	return &csvRecordWriter{w: w, csv: csv.NewWriter(w), header: header}
+}
+
+func (c *csvRecordWriter) write(record exportRecord) error {
+	if !c.wrote {
+		c.wrote = true
+		if err := c.csv.Write(c.header); err != nil {
+			return err
+		}
+	}
+	return c.csv.Write(record.csvRecord())
+}
+
+func (c *csvRecordWriter) flush() error {
+	c.csv.Flush()
+	if err := c.csv.Error(); err != nil {
+		return err
+	}
+	flushHTTP(c.w)
+	return nil
+}
+
+// close writes the header of an empty export too, so the file always has its columns
+func (c *csvRecordWriter) close() error {
+	if !c.wrote {
+		c.wrote = true
+		if err := c.csv.Write(c.header); err != nil {
+			return err
+		}
+	}
+	return c.flush()
+}
+
+// jsonRecordWriter writes a JSON array, or one object per line (NDJSON) when array is false
+type jsonRecordWriter struct {
+	w     io.Writer
+	buf   *bufio.Writer
+	array bool
+	count int
+}
+
+func newJSONRecordWriter(w io.Writer, array bool) *jsonRecordWriter {
+	return &jsonRecordWriter{w: w, buf: bufio.NewWriter(w), array: array}
+}
+
+func (j *jsonRecordWriter) write(record exportRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	if j.array {
+		separator := ",\n"
+		if j.count == 0 {
+			separator = "[\n"
+		}
+		if _, err := j.buf.WriteString(separator); err != nil {
+			return err
+		}
+	}
+	j.count++
+	if _, err := j.buf.Write(data); err != nil {
+		return err
+	}
+	if !j.array {
+		return j.buf.WriteByte('\n')
+	}
+	return nil
+}
+
+func (j *jsonRecordWriter) flush() error {
+	if err := j.buf.Flush(); err != nil {
+		return err
+	}
+	flushHTTP(j.w)
+	return nil
+}
+
+func (j *jsonRecordWriter) close() error {
+	if j.array {
+		end := "\n]\n"
+		if j.count == 0 {
+			end = "[]\n"
+		}
+		if _, err := j.buf.WriteString(end); err != nil {
+			return err
+		}
+	}
+	return j.flush()
+}
+
+// flushHTTP sends what was written so far to the client when w is a response
+func flushHTTP(w io.Writer) {
+	if flusher, ok := w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
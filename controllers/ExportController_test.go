@@ -0,0 +1,147 @@
+package controllers
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/Algoritma-dan-Pemrograman-ITS/Framework-Programming-GIN-GORM/initializers"
+	"github.com/Algoritma-dan-Pemrograman-ITS/Framework-Programming-GIN-GORM/models"
+)
+
+// exportFixture adds two English novels (one with a genre, tags, a bookmark and a review) and a French one
+func exportFixture(t *testing.T) {
+	t.Helper()
+	fantasy := models.Genre{Name: "Fantasy", Slug: "fantasy"}
+	dragons, quest := models.Tag{Name: "dragons"}, models.Tag{Name: "quest"}
+	reader := models.User{Name: "Reader", Email: "reader@example.com"}
+	novels := []*models.Novel{
+		{Title: "The Hobbit", Author: "J.R.R. Tolkien", Language: "english", YearPublished: 1937,
+			Genres: []models.Genre{fantasy}, Tags: []models.Tag{dragons, quest}},
+		{Title: "Dune, Messiah", Author: "Frank Herbert", Language: "english", YearPublished: 1969},
+		{Title: "Le Petit Prince", Author: "Antoine de Saint-Exupéry", Language: "french", YearPublished: 1943},
+	}
+	if err := initializers.DB.Create(&reader).Error; err != nil {
+		t.Fatal(err)
+	}
+	for _, novel := range novels {
+		if err := initializers.DB.Create(novel).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := initializers.DB.Model(&reader).Association("BookmarkedNovels").Append(novels[0]); err != nil {
+		t.Fatal(err)
+	}
+	for _, review := range []models.Review{
+		{UserID: reader.ID, NovelID: novels[0].ID, Rating: 4.5, Comment: "Second breakfast, \"elevenses\""},
+		{UserID: reader.ID, NovelID: novels[2].ID, Rating: 5, Comment: "Magnifique"},
+	} {
+		if err := initializers.DB.Create(&review).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func writeExport(t *testing.T, kind, format string, filters url.Values) []byte {
+	t.Helper()
+	var out bytes.Buffer
+	if err := WriteExport(&out, kind, format, filters); err != nil {
+		t.Fatalf("export %s as %s: %v", kind, format, err)
+	}
+	return out.Bytes()
+}
+
+func TestExportNovelsCSV(t *testing.T) {
+	setupTestDB(t)
+	exportFixture(t)
+
+	records, err := csv.NewReader(bytes.NewReader(writeExport(t, "novels", "csv", url.Values{"language": {"english"}}))).ReadAll()
+	if err != nil {
+		t.Fatalf("export is not valid CSV: %v", err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("got %d records, want the header and 2 novels: %v", len(records), records)
+	}
+	if !reflect.DeepEqual(records[0], exportNovelHeader) {
+		t.Errorf("header %v, want %v", records[0], exportNovelHeader)
+	}
+
+	row := map[string]string{}
+	for i, column := range records[0] {
+		row[column] = records[1][i]
+	}
+	want := map[string]string{
+		"id": "1", "title": "The Hobbit", "author": "J.R.R. Tolkien", "language": "english", "year_published": "1937",
+		"rating": "0", "average_rating": "0", "review_count": "0", "bookmark_count": "1", "genres": "fantasy", "tags": "dragons;quest",
+	}
+	if !reflect.DeepEqual(row, want) {
+		t.Errorf("first row %v, want %v", row, want)
+	}
+	if records[2][1] != "Dune, Messiah" || records[2][9] != "" || records[2][10] != "" {
+		t.Errorf("second row %v, want Dune, Messiah without genres or tags", records[2])
+	}
+}
+
+func TestExportReviewsNDJSON(t *testing.T) {
+	setupTestDB(t)
+	exportFixture(t)
+
+	output := writeExport(t, "reviews", "ndjson", url.Values{"language": {"english"}})
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	var lines []map[string]interface{}
+	for scanner.Scan() {
+		var line map[string]interface{}
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			t.Fatalf("line %q is not a JSON object: %v", scanner.Text(), err)
+		}
+		lines = append(lines, line)
+	}
+	if len(lines) != 1 || !strings.HasSuffix(string(output), "}\n") {
+		t.Fatalf("got %d lines, want one review per line: %q", len(lines), output)
+	}
+
+	keys := make([]string, 0, len(lines[0]))
+	for key := range lines[0] {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	wantKeys := append([]string(nil), exportReviewHeader...)
+	sort.Strings(wantKeys)
+	if !reflect.DeepEqual(keys, wantKeys) {
+		t.Errorf("keys %v, want the CSV columns %v", keys, wantKeys)
+	}
+	if lines[0]["novel_title"] != "The Hobbit" || lines[0]["rating"] != 4.5 || lines[0]["comment"] != `Second breakfast, "elevenses"` {
+		t.Errorf("review %v, want the review of The Hobbit", lines[0])
+	}
+}
+
+func TestExportWithoutMatches(t *testing.T) {
+	setupTestDB(t)
+	exportFixture(t)
+	none := url.Values{"language": {"german"}}
+
+	// CSV keeps its columns, JSON is an empty array and NDJSON empty
+	if got, want := string(writeExport(t, "reviews", "csv", none)), strings.Join(exportReviewHeader, ",")+"\n"; got != want {
+		t.Errorf("csv: got %q, want %q", got, want)
+	}
+	if got := string(writeExport(t, "novels", "json", none)); got != "[]\n" {
+		t.Errorf("json: got %q, want %q", got, "[]\n")
+	}
+	if got := writeExport(t, "novels", "ndjson", none); len(got) != 0 {
+		t.Errorf("ndjson: got %q, want nothing", got)
+	}
+
+	var novels []exportNovel
+	if err := json.Unmarshal(writeExport(t, "novels", "json", nil), &novels); err != nil {
+		t.Fatalf("json export is not an array of novels: %v", err)
+	}
+	if len(novels) != 3 || novels[2].Title != "Le Petit Prince" || novels[1].Genres == nil {
+		t.Errorf("json export %+v, want all 3 novels with genre lists", novels)
+	}
+}
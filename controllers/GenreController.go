@@ -0,0 +1,206 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/Algoritma-dan-Pemrograman-ITS/Framework-Programming-GIN-GORM/initializers"
+	"github.com/Algoritma-dan-Pemrograman-ITS/Framework-Programming-GIN-GORM/models"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+var errUnknownGenre = errors.New("One or more genres do not exist")
+
+// GetGenres lists every genre with the number of novels in it, not counting novels in the trash
+func GetGenres(c *gin.Context) {
+	var genres []struct {
+		models.Genre
+		NovelCount int64 `json:"novel_count"`
+	}
+
+	err := initializers.DB.Model(&models.Genre{}).
+		Select("genres.id, genres.name, genres.slug, COUNT(novels.id) AS novel_count").
+		Joins("LEFT JOIN novel_genres ON novel_genres.genre_id = genres.id").
+		Joins("LEFT JOIN novels ON novels.id = novel_genres.novel_id AND novels.deleted_at IS NULL").
+		Group("genres.id, genres.name, genres.slug").
+		Order("genres.name").
+		Scan(&genres).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"genres": genres})
+}
+
+// Use Raw JSON POST with param: "name"
+func CreateGenre(c *gin.Context) {
+	var body struct {
+		Name string `json:"name" binding:"required,max=100"`
+	}
+
+	if err := c.BindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	genre := models.Genre{Name: strings.TrimSpace(body.Name), Slug: slugify(body.Name)}
+	if genre.Slug == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Genre name must contain letters or digits"})
+		return
+	}
+
+	var existing int64
+	initializers.DB.Model(&models.Genre{}).Where("slug = ?", genre.Slug).Count(&existing)
+	if existing > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Genre already exists"})
+		return
+	}
+
+	if result := initializers.DB.Create(&genre); result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": result.Error.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"genre": genre})
+}
+
+func UpdateGenre(c *gin.Context) {
+	id := c.Param("id")
+	var body struct {
+		Name string `json:"name" binding:"required,max=100"`
+	}
+
+	if err := c.BindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var genre models.Genre
+	if result := initializers.DB.First(&genre, id); result.Error != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Genre not found"})
+		return
+	}
+
+	slug := slugify(body.Name)
+	if slug == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Genre name must contain letters or digits"})
+		return
+	}
+
+	var existing int64
+	initializers.DB.Model(&models.Genre{}).Where("slug = ? AND id <> ?", slug, genre.ID).Count(&existing)
+	if existing > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Genre already exists"})
+		return
+	}
+
+	updates := map[string]interface{}{"name": strings.TrimSpace(body.Name), "slug": slug}
+	if result := initializers.DB.Model(&genre).Updates(updates); result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": result.Error.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"genre": genre})
+}
+
+func DeleteGenre(c *gin.Context) {
+	id := c.Param("id")
+	var genre models.Genre
+	if result := initializers.DB.First(&genre, id); result.Error != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Genre not found"})
+		return
+	}
+
+	err := initializers.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&genre).Association("Novels").Clear(); err != nil {
+			return err
+		}
+		return tx.Delete(&genre).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Genre deleted successfully"})
+}
+
+// slugify turns "Science Fiction" into "science-fiction"
+func slugify(name string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(strings.TrimSpace(name)) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+			dash = false
+		} else if !dash && b.Len() > 0 {
+			b.WriteRune('-')
+			dash = true
+		}
+	}
+	return strings.TrimSuffix(b.String(), "-")
+}
+
+// normalizeTags trims, lowercases and de-duplicates tag names
+func normalizeTags(names []string) []string {
+	seen := make(map[string]bool)
+	result := make([]string, 0, len(names))
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" || len(name) > 100 || seen[name] {
+			continue
+		}
+		seen[name] = true
+		result = append(result, name)
+	}
+	return result
+}
+
+// findGenres loads the genres with the given ids, failing if any of them does not exist
+func findGenres(tx *gorm.DB, ids []uint) ([]models.Genre, error) {
+	genres := []models.Genre{}
+	if len(ids) == 0 {
+		return genres, nil
+	}
+	if err := tx.Where("id IN ?", ids).Find(&genres).Error; err != nil {
+		return nil, err
+	}
+
+	unique := make(map[uint]bool)
+	for _, id := range ids {
+		unique[id] = true
+	}
+	if len(genres) != len(unique) {
+		return nil, errUnknownGenre
+	}
+	return genres, nil
+}
+
+// findOrCreateTags returns the tags with the given names, creating the missing ones
+func findOrCreateTags(tx *gorm.DB, names []string) ([]models.Tag, error) {
+	tags := []models.Tag{}
+	for _, name := range normalizeTags(names) {
+		tag := models.Tag{Name: name}
+		if err := tx.Where("name = ?", name).FirstOrCreate(&tag).Error; err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+	return tags, nil
+}
+
+// splitList reads a comma separated query value ("fantasy,xianxia"), lowercased and without duplicates
+func splitList(value string) []string {
+	var result []string
+	seen := make(map[string]bool)
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.ToLower(strings.TrimSpace(item)); item != "" && !seen[item] {
+			seen[item] = true
+			result = append(result, item)
+		}
+	}
+	return result
+}
@@ -0,0 +1,433 @@
+package controllers
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/Algoritma-dan-Pemrograman-ITS/Framework-Programming-GIN-GORM/initializers"
+	"github.com/Algoritma-dan-Pemrograman-ITS/Framework-Programming-GIN-GORM/models"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"gorm.io/gorm"
+)
+
+const (
+	maxImportBytes = 10 << 20
+	maxImportRows  = 10000
+)
+
+// Returned from the import transaction to roll a dry run back
+var errImportDryRun = errors.New("dry run")
+
+// importRow is one parsed record. Genres are slugs, as genre ids are impractical in a spreadsheet.
+type importRow struct {
+	Line   int
+	Input  novelInput
+	Genres []string
+	Error  string // parse error, the row is rejected without touching the database
+}
+
+type importResult struct {
+	Line    int    `json:"line"`
+	Status  string `json:"status"` // created, updated or rejected
+	NovelID uint   `json:"novel_id,omitempty"`
+	Title   string `json:"title,omitempty"`
+	Author  string `json:"author,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// ImportNovels creates or updates novels in bulk, matching existing ones by title and author (case insensitive).
+// The body is CSV with a header (title, author, language, year_published, genres, tags; lists separated by ";")
+// or JSON lines with the fields of POST /novels plus "genres" (slugs). It can also be a multipart "file".
+// Every row is validated like CreateNovel and reported on its own; ?dry_run=true reports without saving.
+// Example: localhost:8001/novels/import?format=csv&dry_run=true
+func ImportNovels(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportBytes)
+
+	reader := io.Reader(c.Request.Body)
+	name := ""
+	if c.ContentType() == "multipart/form-data" {
+		file, header, err := c.Request.FormFile("file")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Expected the catalog in a \"file\" field"})
+			return
+		}
+		defer file.Close()
+		reader = file
+		name = header.Filename
+	}
+
+	format := importFormat(c.Query("format"), name, c.ContentType())
+	var rows []importRow
+	var err error
+	switch format {
+	case "csv":
+		rows, err = parseImportCSV(reader)
+	case "jsonl":
+		rows, err = parseImportJSON(reader)
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown format, use ?format=csv or ?format=jsonl"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if len(rows) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "The file has no rows"})
+		return
+	}
+	if len(rows) > maxImportRows {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Too many rows, the limit is %d per import", maxImportRows)})
+		return
+	}
+
+	dryRun := c.Query("dry_run") == "true" || c.Query("dry_run") == "1"
+
+	var genres []models.Genre
+	if err := initializers.DB.Find(&genres).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	genresBySlug := make(map[string]models.Genre, len(genres))
+	genresByID := make(map[uint]models.Genre, len(genres))
+	for _, genre := range genres {
+		genresBySlug[genre.Slug] = genre
+		genresByID[genre.ID] = genre
+	}
+
+	// One transaction for the whole file: a rejected row is only skipped, a database error undoes everything
+	results := make([]importResult, 0, len(rows))
+	summary := map[string]int{"created": 0, "updated": 0, "rejected": 0}
+	saved := map[uint]models.Novel{}
+	userID := actingUserID(c)
+	err = initializers.DB.Transaction(func(tx *gorm.DB) error {
+		for _, row := range rows {
+			result := importResult{Line: row.Line, Title: row.Input.Title, Author: row.Input.Author}
+			novel, status, rejection, err := importNovel(tx, row, genresBySlug, genresByID, userID)
+			if err != nil {
+				return fmt.Errorf("line %d: %w", row.Line, err)
+			}
+			result.Status = status
+			result.Error = rejection
+			if status != "rejected" {
+				saved[novel.ID] = novel
+				// Ids of rolled back rows would point at nothing
+				if !dryRun || status == "updated" {
+					result.NovelID = novel.ID
+				}
+			}
+			summary[status]++
+			results = append(results, result)
+		}
+		if dryRun {
+			return errImportDryRun
+		}
+		return nil
+	})
+	if err != nil && err != errImportDryRun {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !dryRun && len(saved) > 0 {
+		novels := make([]models.Novel, 0, len(saved))
+		for _, novel := range saved {
+			novels = append(novels, novel)
+		}
+		indexNovels(novels...)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"dry_run": dryRun,
+		"summary": summary,
+		"rows":    results,
+	})
+}
+
+// importNovel validates one row and creates or updates its novel, recording the revision for userID.
+// A rejected row returns its reason, err is only set for database errors.
+func importNovel(tx *gorm.DB, row importRow, genresBySlug map[string]models.Genre, genresByID map[uint]models.Genre, userID *uint) (models.Novel, string, string, error) {
+	var novel models.Novel
+	if row.Error != "" {
+		return novel, "rejected", row.Error, nil
+	}
+
+	input := row.Input
+	input.Title = strings.TrimSpace(input.Title)
+	input.Author = strings.TrimSpace(input.Author)
+	input.Language = strings.TrimSpace(input.Language)
+	if err := binding.Validator.ValidateStruct(&input); err != nil {
+		return novel, "rejected", err.Error(), nil
+	}
+
+	genres := []models.Genre{}
+	seen := map[uint]bool{}
+	addGenre := func(genre models.Genre) {
+		if !seen[genre.ID] {
+			seen[genre.ID] = true
+			genres = append(genres, genre)
+		}
+	}
+	var unknown []string
+	for _, id := range input.GenreIDs {
+		if genre, ok := genresByID[id]; ok {
+			addGenre(genre)
+		} else {
+			unknown = append(unknown, strconv.FormatUint(uint64(id), 10))
+		}
+	}
+	for _, slug := range row.Genres {
+		if genre, ok := genresBySlug[slug]; ok {
+			addGenre(genre)
+		} else {
+			unknown = append(unknown, slug)
+		}
+	}
+	if len(unknown) > 0 {
+		return novel, "rejected", errUnknownGenre.Error() + ": " + strings.Join(unknown, ", "), nil
+	}
+
+	tags, err := findOrCreateTags(tx, input.Tags)
+	if err != nil {
+		return novel, "", "", err
+	}
+
+	// Title and author are the natural key, novels have no ISBN to match on. The trash is searched too,
+	// so a removed novel is not silently duplicated.
+	result := tx.Unscoped().Where("LOWER(title) = ? AND LOWER(author) = ?", strings.ToLower(input.Title), strings.ToLower(input.Author)).
+		Limit(1).Find(&novel)
+	if result.Error != nil {
+		return novel, "", "", result.Error
+	}
+	if novel.DeletedAt.Valid {
+		return novel, "rejected", "Matches novel " + strconv.FormatUint(uint64(novel.ID), 10) + " in the trash, restore it first", nil
+	}
+
+	if result.RowsAffected == 0 {
+		novel = models.Novel{
+			Title:         input.Title,
+			Author:        input.Author,
+			Language:      input.Language,
+			YearPublished: input.YearPublished,
+			Genres:        genres,
+			Tags:          tags,
+		}
+		if err := tx.Create(&novel).Error; err != nil {
+			return novel, "", "", err
+		}
+		revision := models.NovelRevision{NovelID: novel.ID, UserID: userID, Action: models.RevisionCreate}
+		if err := recordNovelRevision(tx, nil, revision); err != nil {
+			return novel, "", "", err
+		}
+		return novel, "created", "", nil
+	}
+
+	before, err := loadNovelSnapshot(tx, novel.ID)
+	if err != nil {
+		return novel, "", "", err
+	}
+
+	// Genres and tags are only replaced when the row lists some, an empty cell keeps the current ones
+	novel.Language = input.Language
+	novel.YearPublished = input.YearPublished
+	if err := tx.Model(&novel).Updates(map[string]interface{}{
+		"language":       input.Language,
+		"year_published": input.YearPublished,
+	}).Error; err != nil {
+		return novel, "", "", err
+	}
+	if len(input.GenreIDs) > 0 || len(row.Genres) > 0 {
+		if err := tx.Model(&novel).Association("Genres").Replace(genres); err != nil {
+			return novel, "", "", err
+		}
+	}
+	if len(tags) > 0 {
+		if err := tx.Model(&novel).Association("Tags").Replace(tags); err != nil {
+			return novel, "", "", err
+		}
+	}
+	revision := models.NovelRevision{NovelID: novel.ID, UserID: userID, Action: models.RevisionUpdate}
+	if err := recordNovelRevision(tx, before, revision); err != nil {
+		return novel, "", "", err
+	}
+	return novel, "updated", "", nil
+}
+
+// importFormat picks csv or jsonl from ?format=, the uploaded file name or the Content-Type
+func importFormat(query, filename, contentType string) string {
+	format := strings.ToLower(query)
+	if format == "" {
+		format = strings.TrimPrefix(strings.ToLower(filepath.Ext(filename)), ".")
+	}
+	if format == "" {
+		switch contentType {
+		case "text/csv":
+			format = "csv"
+		case "application/x-ndjson", "application/jsonl", "application/json":
+			format = "jsonl"
+		}
+	}
+	switch format {
+	case "ndjson", "json":
+		return "jsonl"
+	}
+	return format
+}
+
+func parseImportCSV(r io.Reader) ([]importRow, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, errors.New("Could not read the CSV header: " + err.Error())
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		columns[name] = i
+	}
+	for _, required := range []string{"title", "author", "language", "year_published"} {
+		if _, ok := columns[required]; !ok {
+			return nil, errors.New("The CSV header must include title, author, language and year_published")
+		}
+	}
+
+	var rows []importRow
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			rows = append(rows, importRow{Line: parseErr.Line, Error: parseErr.Err.Error()})
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		line, _ := reader.FieldPos(0)
+		field := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		row := importRow{
+			Line: line,
+			Input: novelInput{
+				Title:    field("title"),
+				Author:   field("author"),
+				Language: field("language"),
+				Tags:     splitCell(field("tags")),
+			},
+			Genres: normalizeSlugs(splitCell(field("genres"))),
+		}
+		if year := field("year_published"); year != "" {
+			if row.Input.YearPublished, err = strconv.Atoi(year); err != nil {
+				row.Error = "year_published must be a number"
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// parseImportJSON reads JSON lines, or a single JSON array of the same objects
+func parseImportJSON(r io.Reader) ([]importRow, error) {
+	buffered := bufio.NewReader(r)
+	first, err := firstNonSpace(buffered)
+	if err != nil {
+		return nil, err
+	}
+
+	type jsonRow struct {
+		novelInput
+		Genres []string `json:"genres"`
+	}
+	decode := func(line int, data []byte) importRow {
+		var item jsonRow
+		if err := json.Unmarshal(data, &item); err != nil {
+			return importRow{Line: line, Error: "Invalid JSON: " + err.Error()}
+		}
+		return importRow{Line: line, Input: item.novelInput, Genres: normalizeSlugs(item.Genres)}
+	}
+
+	var rows []importRow
+	if first == '[' {
+		var items []json.RawMessage
+		if err := json.NewDecoder(buffered).Decode(&items); err != nil {
+			return nil, errors.New("Invalid JSON array: " + err.Error())
+		}
+		for i, item := range items {
+			rows = append(rows, decode(i+1, item))
+		}
+		return rows, nil
+	}
+
+	scanner := bufio.NewScanner(buffered)
+	scanner.Buffer(make([]byte, 64*1024), 1<<20)
+	line := 0
+	for scanner.Scan() {
+		line++
+		data := bytes.TrimSpace(scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+		rows = append(rows, decode(line, data))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+func firstNonSpace(r *bufio.Reader) (byte, error) {
+	for {
+		b, err := r.ReadByte()
+		if err == io.EOF {
+			return 0, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+		if b != ' ' && b != '\t' && b != '\r' && b != '\n' {
+			return b, r.UnreadByte()
+		}
+	}
+}
+
+// splitCell splits a spreadsheet cell like "fantasy; adventure"
+func splitCell(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ";") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func normalizeSlugs(slugs []string) []string {
+	result := make([]string, 0, len(slugs))
+	for _, slug := range slugs {
+		if slug = strings.ToLower(strings.TrimSpace(slug)); slug != "" {
+			result = append(result, slug)
+		}
+	}
+	return result
+}
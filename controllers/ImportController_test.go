@@ -0,0 +1,121 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Algoritma-dan-Pemrograman-ITS/Framework-Programming-GIN-GORM/initializers"
+	"github.com/Algoritma-dan-Pemrograman-ITS/Framework-Programming-GIN-GORM/models"
+	"github.com/gin-gonic/gin"
+)
+
+type importResponse struct {
+	Summary map[string]int `json:"summary"`
+	Rows    []importResult `json:"rows"`
+}
+
+func importCatalog(t *testing.T, query, body string) importResponse {
+	t.Helper()
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/novels/import?"+query, strings.NewReader(body))
+	ImportNovels(c)
+	if w.Code != http.StatusOK {
+		t.Fatalf("import: status %d: %s", w.Code, w.Body)
+	}
+
+	var response importResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatal(err)
+	}
+	return response
+}
+
+func countNovels(t *testing.T) int64 {
+	t.Helper()
+	var count int64
+	initializers.DB.Model(&models.Novel{}).Count(&count)
+	return count
+}
+
+const importCSV = `title,author,language,year_published,genres,tags
+The Hobbit,J.R.R. Tolkien,english,1937,fantasy,dragons;quest
+,No Title,english,2000,,
+Dune,Frank Herbert,english,soon,,
+Emma,Jane Austen,english,1815,romance,
+`
+
+func TestImportValidatesEachRow(t *testing.T) {
+	setupTestDB(t)
+	initializers.DB.Create(&models.Genre{Name: "Fantasy", Slug: "fantasy"})
+
+	response := importCatalog(t, "format=csv", importCSV)
+	want := []string{"created", "rejected", "rejected", "rejected"}
+	if len(response.Rows) != len(want) {
+		t.Fatalf("got %d rows, want %d", len(response.Rows), len(want))
+	}
+	for i, row := range response.Rows {
+		if row.Status != want[i] {
+			t.Errorf("line %d: status %s (%s), want %s", row.Line, row.Status, row.Error, want[i])
+		}
+		if row.Status == "rejected" && row.Error == "" {
+			t.Errorf("line %d rejected without a reason", row.Line)
+		}
+	}
+	if !strings.Contains(response.Rows[3].Error, "romance") {
+		t.Errorf("unknown genre error %q does not name the genre", response.Rows[3].Error)
+	}
+	if countNovels(t) != 1 {
+		t.Errorf("got %d novels, want 1", countNovels(t))
+	}
+}
+
+func TestImportDryRunAndUpdate(t *testing.T) {
+	setupTestDB(t)
+	body := "title,author,language,year_published\nThe Hobbit,J.R.R. Tolkien,english,1937\n"
+
+	if response := importCatalog(t, "format=csv&dry_run=true", body); response.Summary["created"] != 1 {
+		t.Fatalf("dry run summary %v, want 1 created", response.Summary)
+	}
+	if countNovels(t) != 0 {
+		t.Fatal("dry run saved novels")
+	}
+
+	importCatalog(t, "format=csv", body)
+
+	// Same title and author in another case: the novel is updated, not duplicated
+	response := importCatalog(t, "format=jsonl", `{"title":"the hobbit","author":"j.r.r. tolkien","language":"english","year_published":1951}`)
+	if response.Summary["updated"] != 1 || countNovels(t) != 1 {
+		t.Fatalf("summary %v with %d novels, want 1 updated novel", response.Summary, countNovels(t))
+	}
+
+	var novel models.Novel
+	initializers.DB.First(&novel)
+	if novel.YearPublished != 1951 || novel.Title != "The Hobbit" {
+		t.Errorf("got %q (%d), want The Hobbit (1951)", novel.Title, novel.YearPublished)
+	}
+	if revisions := novelRevisions(t, novel.ID); len(revisions) != 2 || revisions[1].Action != models.RevisionUpdate {
+		t.Errorf("got %d revisions, want a create and an update", len(revisions))
+	}
+}
+
+func TestImportRejectsTrashedMatch(t *testing.T) {
+	setupTestDB(t)
+	novel := models.Novel{Title: "The Hobbit", Author: "J.R.R. Tolkien", Language: "english", YearPublished: 1937}
+	initializers.DB.Create(&novel)
+	initializers.DB.Delete(&novel)
+
+	response := importCatalog(t, "format=csv", "title,author,language,year_published\nThe Hobbit,J.R.R. Tolkien,english,1951\n")
+	if response.Rows[0].Status != "rejected" || !strings.Contains(response.Rows[0].Error, "trash") {
+		t.Errorf("got %s (%s), want rejected because of the trash", response.Rows[0].Status, response.Rows[0].Error)
+	}
+
+	var count int64
+	initializers.DB.Unscoped().Model(&models.Novel{}).Count(&count)
+	if count != 1 {
+		t.Errorf("got %d novels including the trash, want 1", count)
+	}
+}
@@ -0,0 +1,15 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/Algoritma-dan-Pemrograman-ITS/Framework-Programming-GIN-GORM/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// GetJWKS publishes the public halves of the signing keys so other services can verify
+// access tokens without sharing a secret. Empty when tokens are signed with JWT_SECRET.
+func GetJWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, utils.JWKS())
+}
@@ -0,0 +1,190 @@
+package controllers
+
+import (
+	"math"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Algoritma-dan-Pemrograman-ITS/Framework-Programming-GIN-GORM/initializers"
+	"github.com/Algoritma-dan-Pemrograman-ITS/Framework-Programming-GIN-GORM/models"
+	"github.com/Algoritma-dan-Pemrograman-ITS/Framework-Programming-GIN-GORM/utils"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Defaults, overridable with LOGIN_MAX_FAILURES, LOGIN_MAX_FAILURES_IP, LOGIN_FAILURE_WINDOW,
+// LOGIN_LOCKOUT_BASE and LOGIN_LOCKOUT_MAX
+const (
+	defaultMaxAccountFailures = 5
+	defaultMaxIPFailures      = 20
+	defaultFailureWindow      = 15 * time.Minute
+	defaultLockoutBase        = time.Minute
+	defaultLockoutMax         = time.Hour
+)
+
+func throttleLimit(kind string) int {
+	key, fallback := "LOGIN_MAX_FAILURES", defaultMaxAccountFailures
+	if kind == models.ThrottleIP {
+		key, fallback = "LOGIN_MAX_FAILURES_IP", defaultMaxIPFailures
+	}
+	if v, err := strconv.Atoi(os.Getenv(key)); err == nil && v > 0 {
+		return v
+	}
+	return fallback
+}
+
+// lockoutDuration doubles for every failure past the limit: base, 2*base, 4*base ... up to max
+func lockoutDuration(failures, limit int) time.Duration {
+	base := utils.DurationFromEnv("LOGIN_LOCKOUT_BASE", defaultLockoutBase)
+	max := utils.DurationFromEnv("LOGIN_LOCKOUT_MAX", defaultLockoutMax)
+
+	exponent := failures - limit
+	if exponent > 30 {
+		exponent = 30
+	}
+	d := time.Duration(float64(base) * math.Pow(2, float64(exponent)))
+	if d > max || d <= 0 {
+		d = max
+	}
+	return d
+}
+
+type throttleKey struct {
+	kind string
+	key  string
+}
+
+func loginThrottleKeys(c *gin.Context, email string) []throttleKey {
+	keys := []throttleKey{{kind: models.ThrottleIP, key: c.ClientIP()}}
+	if email != "" {
+		keys = append(keys, throttleKey{kind: models.ThrottleAccount, key: strings.ToLower(strings.TrimSpace(email))})
+	}
+	return keys
+}
+
+// checkLoginThrottle answers 429 with Retry-After and returns false when the email or IP is locked out
+func checkLoginThrottle(c *gin.Context, email string) bool {
+	now := time.Now()
+	var retryAfter time.Duration
+
+	for _, k := range loginThrottleKeys(c, email) {
+		var throttle models.LoginThrottle
+		if err := initializers.DB.Where("kind = ? AND identifier = ?", k.kind, k.key).First(&throttle).Error; err != nil {
+			continue
+		}
+		if throttle.LockedUntil != nil && throttle.LockedUntil.After(now) {
+			if wait := throttle.LockedUntil.Sub(now); wait > retryAfter {
+				retryAfter = wait
+			}
+		}
+	}
+
+	if retryAfter > 0 {
+		seconds := int(math.Ceil(retryAfter.Seconds()))
+		c.Header("Retry-After", strconv.Itoa(seconds))
+		c.JSON(http.StatusTooManyRequests, gin.H{
+			"error":       "Too many failed login attempts, try again later",
+			"retry_after": seconds,
+		})
+		return false
+	}
+	return true
+}
+
+// recordLoginFailure counts a failed attempt for the IP and the email (registered or not,
+// so lockouts do not reveal which accounts exist)
+func recordLoginFailure(c *gin.Context, email string) {
+	for _, k := range loginThrottleKeys(c, email) {
+		incrementThrottle(k)
+	}
+}
+
+// recordLoginSuccess clears the account counter. The IP counter is left to expire on its own,
+// otherwise one valid account would let an attacker reset it.
+func recordLoginSuccess(email string) {
+	initializers.DB.Where("kind = ? AND identifier = ?", models.ThrottleAccount, strings.ToLower(strings.TrimSpace(email))).
+		Delete(&models.LoginThrottle{})
+}
+
+// incrementThrottle uses an optimistic update on the failure count so concurrent API instances do not lose attempts
+func incrementThrottle(k throttleKey) {
+	window := utils.DurationFromEnv("LOGIN_FAILURE_WINDOW", defaultFailureWindow)
+	limit := throttleLimit(k.kind)
+
+	for attempt := 0; attempt < 3; attempt++ {
+		now := time.Now()
+
+		var throttle models.LoginThrottle
+		err := initializers.DB.Where("kind = ? AND identifier = ?", k.kind, k.key).First(&throttle).Error
+		if err == gorm.ErrRecordNotFound {
+			throttle = models.LoginThrottle{Kind: k.kind, Identifier: k.key, Failures: 1, LastFailureAt: now}
+			if limit <= 1 {
+				lockedUntil := now.Add(lockoutDuration(1, limit))
+				throttle.LockedUntil = &lockedUntil
+			}
+			if initializers.DB.Create(&throttle).Error == nil {
+				return
+			}
+			continue // another instance created it first, retry as an update
+		}
+		if err != nil {
+			return
+		}
+
+		failures := throttle.Failures + 1
+		// Old failures outside the window are forgotten once any lockout is over, however many there were
+		stillLocked := throttle.LockedUntil != nil && throttle.LockedUntil.After(now)
+		if !stillLocked && now.Sub(throttle.LastFailureAt) > window {
+			failures = 1
+		}
+
+		updates := map[string]interface{}{"failures": failures, "last_failure_at": now}
+		if failures >= limit {
+			updates["locked_until"] = now.Add(lockoutDuration(failures, limit))
+		}
+
+		result := initializers.DB.Model(&models.LoginThrottle{}).
+			Where("id = ? AND failures = ?", throttle.ID, throttle.Failures).
+			Updates(updates)
+		if result.Error != nil || result.RowsAffected > 0 {
+			return
+		}
+	}
+}
+
+// GetLockouts lists throttle counters for admins. ?locked=true shows only active lockouts.
+func GetLockouts(c *gin.Context) {
+	query := initializers.DB.Model(&models.LoginThrottle{})
+	if c.Query("locked") == "true" {
+		query = query.Where("locked_until > ?", time.Now())
+	}
+	if kind := c.Query("kind"); kind != "" {
+		query = query.Where("kind = ?", kind)
+	}
+
+	var throttles []models.LoginThrottle
+	if err := query.Order("last_failure_at DESC").Find(&throttles).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"lockouts": throttles})
+}
+
+// ClearLockout removes a throttle counter, unlocking the account or IP immediately
+func ClearLockout(c *gin.Context) {
+	result := initializers.DB.Delete(&models.LoginThrottle{}, c.Param("id"))
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": result.Error.Error()})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Lockout not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Lockout cleared successfully"})
+}
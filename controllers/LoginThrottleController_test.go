@@ -0,0 +1,123 @@
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Algoritma-dan-Pemrograman-ITS/Framework-Programming-GIN-GORM/initializers"
+	"github.com/Algoritma-dan-Pemrograman-ITS/Framework-Programming-GIN-GORM/models"
+)
+
+func loadThrottle(t *testing.T, k throttleKey) models.LoginThrottle {
+	t.Helper()
+	var throttle models.LoginThrottle
+	if err := initializers.DB.Where("kind = ? AND identifier = ?", k.kind, k.key).First(&throttle).Error; err != nil {
+		t.Fatalf("loading throttle: %v", err)
+	}
+	return throttle
+}
+
+// ageThrottle moves the last failure and the lockout into the past, as if time had passed
+func ageThrottle(t *testing.T, k throttleKey, by time.Duration) {
+	t.Helper()
+	throttle := loadThrottle(t, k)
+	updates := map[string]interface{}{"last_failure_at": throttle.LastFailureAt.Add(-by)}
+	if throttle.LockedUntil != nil {
+		updates["locked_until"] = throttle.LockedUntil.Add(-by)
+	}
+	if err := initializers.DB.Model(&throttle).Updates(updates).Error; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoginThrottleLocksAtLimit(t *testing.T) {
+	setupTestDB(t)
+	t.Setenv("LOGIN_MAX_FAILURES", "3")
+	k := throttleKey{kind: models.ThrottleAccount, key: "alice@example.com"}
+
+	for i := 0; i < 2; i++ {
+		incrementThrottle(k)
+	}
+	if throttle := loadThrottle(t, k); throttle.LockedUntil != nil {
+		t.Fatalf("locked after %d failures, limit is 3", throttle.Failures)
+	}
+
+	incrementThrottle(k)
+	throttle := loadThrottle(t, k)
+	if throttle.Failures != 3 || throttle.LockedUntil == nil || !throttle.LockedUntil.After(time.Now()) {
+		t.Fatalf("got failures %d locked_until %v, want 3 and a lockout", throttle.Failures, throttle.LockedUntil)
+	}
+}
+
+func TestLoginThrottleResetsAfterWindowAndLockout(t *testing.T) {
+	setupTestDB(t)
+	t.Setenv("LOGIN_MAX_FAILURES_IP", "3")
+	t.Setenv("LOGIN_FAILURE_WINDOW", "15m")
+	t.Setenv("LOGIN_LOCKOUT_BASE", "1m")
+	t.Setenv("LOGIN_LOCKOUT_MAX", "1h")
+	k := throttleKey{kind: models.ThrottleIP, key: "192.0.2.1"}
+
+	// Lock it once, well past the limit
+	for i := 0; i < 5; i++ {
+		incrementThrottle(k)
+	}
+	if throttle := loadThrottle(t, k); throttle.Failures != 5 || throttle.LockedUntil == nil {
+		t.Fatalf("got failures %d locked_until %v, want 5 and a lockout", throttle.Failures, throttle.LockedUntil)
+	}
+
+	// Window and lockout both over: the next failure starts a new count instead of locking again
+	ageThrottle(t, k, 2*time.Hour)
+	incrementThrottle(k)
+	throttle := loadThrottle(t, k)
+	if throttle.Failures != 1 {
+		t.Errorf("got failures %d after the window passed, want 1", throttle.Failures)
+	}
+	if throttle.LockedUntil != nil && throttle.LockedUntil.After(time.Now()) {
+		t.Errorf("locked again until %v by a single failure", throttle.LockedUntil)
+	}
+}
+
+func TestLoginThrottleEscalatesWithinWindow(t *testing.T) {
+	setupTestDB(t)
+	t.Setenv("LOGIN_MAX_FAILURES", "3")
+	t.Setenv("LOGIN_FAILURE_WINDOW", "15m")
+	t.Setenv("LOGIN_LOCKOUT_BASE", "1m")
+	t.Setenv("LOGIN_LOCKOUT_MAX", "1h")
+	k := throttleKey{kind: models.ThrottleAccount, key: "bob@example.com"}
+
+	for i := 0; i < 3; i++ {
+		incrementThrottle(k)
+	}
+
+	// The 1m lockout is over but the last failure is still inside the window: the count keeps going
+	ageThrottle(t, k, 2*time.Minute)
+	incrementThrottle(k)
+	throttle := loadThrottle(t, k)
+	if throttle.Failures != 4 {
+		t.Fatalf("got failures %d, want 4", throttle.Failures)
+	}
+	if wait := time.Until(*throttle.LockedUntil); wait < time.Minute+30*time.Second {
+		t.Errorf("lockout %v did not double", wait)
+	}
+}
+
+func TestLockoutDuration(t *testing.T) {
+	t.Setenv("LOGIN_LOCKOUT_BASE", "1m")
+	t.Setenv("LOGIN_LOCKOUT_MAX", "1h")
+
+	tests := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{5, time.Minute},
+		{6, 2 * time.Minute},
+		{8, 8 * time.Minute},
+		{20, time.Hour},
+		{1000, time.Hour},
+	}
+	for _, tt := range tests {
+		if got := lockoutDuration(tt.failures, 5); got != tt.want {
+			t.Errorf("lockoutDuration(%d, 5) = %v, want %v", tt.failures, got, tt.want)
+		}
+	}
+}
@@ -2,22 +2,31 @@ package controllers
 
 import (
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/Algoritma-dan-Pemrograman-ITS/Framework-Programming-GIN-GORM/initializers"
 	"github.com/Algoritma-dan-Pemrograman-ITS/Framework-Programming-GIN-GORM/models"
+	"github.com/Algoritma-dan-Pemrograman-ITS/Framework-Programming-GIN-GORM/utils"
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
+// novelInput is the body of CreateNovel, also used to validate every row of ImportNovels
+type novelInput struct {
+	Title         string   `json:"title" binding:"required"`
+	Author        string   `json:"author" binding:"required"`
+	Language      string   `json:"language" binding:"required"`
+	YearPublished int      `json:"year_published" binding:"required"`
+	GenreIDs      []uint   `json:"genre_ids"`
+	Tags          []string `json:"tags"`
+}
+
 // Capitalized (Exported) so routes can see it
 func CreateNovel(c *gin.Context) {
-	var body struct {
-		Title         string  `json:"title" binding:"required"`
-		Author        string  `json:"author" binding:"required"`
-		Rating        float64 `json:"rating" binding:"required"`
-		Language      string  `json:"language" binding:"required"`
-		YearPublished int     `json:"year_published" binding:"required"`
-	}
+	var body novelInput
 
 	if err := c.BindJSON(&body); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -27,52 +36,85 @@ func CreateNovel(c *gin.Context) {
 	novel := models.Novel{
 		Title:         body.Title,
 		Author:        body.Author,
-		Rating:        body.Rating,
 		Language:      body.Language,
 		YearPublished: body.YearPublished,
 	}
 
-	if result := initializers.DB.Create(&novel); result.Error != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": result.Error.Error()})
+	err := initializers.DB.Transaction(func(tx *gorm.DB) error {
+		genres, err := findGenres(tx, body.GenreIDs)
+		if err != nil {
+			return err
+		}
+		tags, err := findOrCreateTags(tx, body.Tags)
+		if err != nil {
+			return err
+		}
+		novel.Genres = genres
+		novel.Tags = tags
+		if err := tx.Create(&novel).Error; err != nil {
+			return err
+		}
+		return recordNovelRevision(tx, nil, models.NovelRevision{
+			NovelID: novel.ID,
+			UserID:  actingUserID(c),
+			Action:  models.RevisionCreate,
+		})
+	})
+	if err == errUnknownGenre {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
+	indexNovels(novel)
 	c.JSON(http.StatusCreated, gin.H{"novel": novel}) // Use 201 Created for new items
 }
 
+// Public sort names for GET /novels and the column each one maps to
+var novelSortFields = map[string]string{
+	"title":          "title",
+	"rating":         "rating",
+	"year_published": "year_published",
+	"created":        "id",
+}
+
+// Example: /novels?author=tolkien&sort=rating&order=desc&page=2&page_size=10
 func GetAllNovels(c *gin.Context) {
+	pagination, errMsg := utils.ParsePagination(c, novelSortFields, "created")
+	if errMsg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errMsg})
+		return
+	}
+
 	var novels []models.Novel
-	query := initializers.DB.Model(&models.Novel{})
+	query := filterNovels(initializers.DB.Model(&models.Novel{}), c.Request.URL.Query())
 
-	// Filtering
-	if title := c.Query("title"); title != "" {
-		query = query.Where("title LIKE ?", "%"+title+"%")
-	}
-	if author := c.Query("author"); author != "" {
-		query = query.Where("author LIKE ?", "%"+author+"%")
-	}
-	if language := c.Query("language"); language != "" {
-		query = query.Where("language = ?", language)
-	}
-	if year := c.Query("year_published"); year != "" {
-		if yearInt, err := strconv.Atoi(year); err == nil {
-			query = query.Where("year_published = ?", yearInt)
-		}
+	// Count before pagination so total reflects every matching row
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
 	}
 
-	if err := query.Find(&novels).Error; err != nil {
+	if err := pagination.Apply(query, "novels").Preload("Genres").Preload("Tags").Find(&novels).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"novels": novels})
+	c.JSON(http.StatusOK, gin.H{
+		"novels":     novels,
+		"pagination": pagination.Info(c, total),
+	})
 }
 
 func GetNovelByID(c *gin.Context) {
 	id := c.Param("id")
 	var novel models.Novel
 
-	if err := initializers.DB.First(&novel, id).Error; err != nil {
+	if err := initializers.DB.Preload("Genres").Preload("Tags").First(&novel, id).Error; err != nil {
 		// Use 404 for Not Found
 		c.JSON(http.StatusNotFound, gin.H{"error": "Novel not found"})
 		return
@@ -84,11 +126,12 @@ func GetNovelByID(c *gin.Context) {
 func UpdateNovel(c *gin.Context) {
 	id := c.Param("id")
 	var body struct {
-		Title         string   `json:"title"`
-		Author        string   `json:"author"`
-		Rating        *float64 `json:"rating"` // Pointer to handle 0 values
-		Language      string   `json:"language"`
-		YearPublished *int     `json:"year_published"` // Pointer to handle 0 values
+		Title         string    `json:"title"`
+		Author        string    `json:"author"`
+		Language      string    `json:"language"`
+		YearPublished *int      `json:"year_published"` // Pointer to handle 0 values
+		GenreIDs      *[]uint   `json:"genre_ids"`      // Replaces the genres when present
+		Tags          *[]string `json:"tags"`           // Replaces the tags when present
 	}
 
 	if err := c.BindJSON(&body); err != nil {
@@ -110,25 +153,62 @@ func UpdateNovel(c *gin.Context) {
 	if body.Author != "" {
 		updates["author"] = body.Author
 	}
-	// Check if pointer is not nil (meaning user explicitly sent a value)
-	if body.Rating != nil {
-		updates["rating"] = *body.Rating
-	}
 	if body.Language != "" {
 		updates["language"] = body.Language
 	}
+	// Check if pointer is not nil (meaning user explicitly sent a value)
 	if body.YearPublished != nil {
 		updates["year_published"] = *body.YearPublished
 	}
 
-	if result := initializers.DB.Model(&novel).Updates(updates); result.Error != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": result.Error.Error()})
+	err := initializers.DB.Transaction(func(tx *gorm.DB) error {
+		before, err := loadNovelSnapshot(tx, novel.ID)
+		if err != nil {
+			return err
+		}
+		if err := tx.Model(&novel).Updates(updates).Error; err != nil {
+			return err
+		}
+		if body.GenreIDs != nil {
+			genres, err := findGenres(tx, *body.GenreIDs)
+			if err != nil {
+				return err
+			}
+			if err := tx.Model(&novel).Association("Genres").Replace(genres); err != nil {
+				return err
+			}
+		}
+		if body.Tags != nil {
+			tags, err := findOrCreateTags(tx, *body.Tags)
+			if err != nil {
+				return err
+			}
+			if err := tx.Model(&novel).Association("Tags").Replace(tags); err != nil {
+				return err
+			}
+		}
+		return recordNovelRevision(tx, before, models.NovelRevision{
+			NovelID: novel.ID,
+			UserID:  actingUserID(c),
+			Action:  models.RevisionUpdate,
+		})
+	})
+	if err == errUnknownGenre {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
+	initializers.DB.Preload("Genres").Preload("Tags").First(&novel, novel.ID)
+	indexNovels(novel)
 	c.JSON(http.StatusOK, gin.H{"novel": novel})
 }
 
+// RemoveNovel moves a novel to the trash. Its chapters and reviews are kept so it can be restored
+// with RestoreNovel until PurgeTrash deletes it for good.
 func RemoveNovel(c *gin.Context) {
 	id := c.Param("id")
 	var novel models.Novel
@@ -136,9 +216,82 @@ func RemoveNovel(c *gin.Context) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Novel not found"})
 		return
 	}
-	if result := initializers.DB.Delete(&novel); result.Error != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": result.Error.Error()})
+	if err := initializers.DB.Delete(&novel).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"message": "Novel deleted successfully"})
+	unindexNovels(novel.ID)
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Novel moved to trash",
+		"purge_at": time.Now().Add(trashRetention()),
+	})
+}
+
+// filterNovels applies the filters of GET /novels (title, author, language, year_published, genres and tags).
+// It takes the parsed query string so the export CLI can pass the same filters.
+func filterNovels(query *gorm.DB, params url.Values) *gorm.DB {
+	if title := params.Get("title"); title != "" {
+		query = query.Where(utils.ContainsClause("novels.title"), utils.ContainsPattern(title))
+	}
+	if author := params.Get("author"); author != "" {
+		query = query.Where(utils.ContainsClause("novels.author"), utils.ContainsPattern(author))
+	}
+	if language := params.Get("language"); language != "" {
+		query = query.Where("LOWER(novels.language) = ?", strings.ToLower(language))
+	}
+	if year := params.Get("year_published"); year != "" {
+		if yearInt, err := strconv.Atoi(year); err == nil {
+			query = query.Where("novels.year_published = ?", yearInt)
+		}
+	}
+	return filterByGenresAndTags(params, query)
+}
+
+// filterByGenresAndTags applies ?genre= / ?tag= (comma separated genre slugs / tag names).
+// By default a novel matches if it has any of them; ?genre_mode=all / ?tag_mode=all require every one.
+// ?exclude_genre= / ?exclude_tag= drop novels having any of the listed values.
+func filterByGenresAndTags(params url.Values, query *gorm.DB) *gorm.DB {
+	genreLink := linkFilter{table: "novel_genres", key: "genre_id", target: "genres", column: "slug"}
+	tagLink := linkFilter{table: "novel_tags", key: "tag_id", target: "tags", column: "name"}
+
+	if genres := splitList(params.Get("genre")); len(genres) > 0 {
+		query = genreLink.include(query, genres, params.Get("genre_mode") == "all")
+	}
+	if genres := splitList(params.Get("exclude_genre")); len(genres) > 0 {
+		query = genreLink.exclude(query, genres)
+	}
+	if tags := splitList(params.Get("tag")); len(tags) > 0 {
+		query = tagLink.include(query, tags, params.Get("tag_mode") == "all")
+	}
+	if tags := splitList(params.Get("exclude_tag")); len(tags) > 0 {
+		query = tagLink.exclude(query, tags)
+	}
+	return query
+}
+
+// linkFilter describes a many-to-many join table (novel_genres, novel_tags) and the column we match on
+type linkFilter struct {
+	table  string
+	key    string
+	target string
+	column string
+}
+
+func (f linkFilter) novelIDs(values []string) *gorm.DB {
+	return initializers.DB.Table(f.table).
+		Select(f.table+".novel_id").
+		Joins("JOIN "+f.target+" ON "+f.target+".id = "+f.table+"."+f.key).
+		Where(f.target+"."+f.column+" IN ?", values)
+}
+
+func (f linkFilter) include(query *gorm.DB, values []string, all bool) *gorm.DB {
+	sub := f.novelIDs(values)
+	if all {
+		sub = sub.Group(f.table+".novel_id").Having("COUNT(DISTINCT "+f.target+".id) = ?", len(values))
+	}
+	return query.Where("novels.id IN (?)", sub)
+}
+
+func (f linkFilter) exclude(query *gorm.DB, values []string) *gorm.DB {
+	return query.Where("novels.id NOT IN (?)", f.novelIDs(values))
 }
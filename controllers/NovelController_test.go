@@ -0,0 +1,88 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/Algoritma-dan-Pemrograman-ITS/Framework-Programming-GIN-GORM/initializers"
+	"github.com/Algoritma-dan-Pemrograman-ITS/Framework-Programming-GIN-GORM/models"
+	"github.com/gin-gonic/gin"
+)
+
+// listNovelTitles calls GET /novels with query and returns the sorted titles and the reported total
+func listNovelTitles(t *testing.T, query string) ([]string, int64) {
+	t.Helper()
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/novels?"+query, nil)
+	GetAllNovels(c)
+	if w.Code != http.StatusOK {
+		t.Fatalf("%s: status %d: %s", query, w.Code, w.Body)
+	}
+
+	var response struct {
+		Novels     []models.Novel `json:"novels"`
+		Pagination struct {
+			Total int64 `json:"total"`
+		} `json:"pagination"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatal(err)
+	}
+	titles := []string{}
+	for _, novel := range response.Novels {
+		titles = append(titles, novel.Title)
+	}
+	sort.Strings(titles)
+	return titles, response.Pagination.Total
+}
+
+func TestGetAllNovelsGenreAndTagFilters(t *testing.T) {
+	setupTestDB(t)
+	fantasy := models.Genre{Name: "Fantasy", Slug: "fantasy"}
+	scifi := models.Genre{Name: "Science Fiction", Slug: "science-fiction"}
+	romance := models.Genre{Name: "Romance", Slug: "romance"}
+	dragons, quest, desert := models.Tag{Name: "dragons"}, models.Tag{Name: "quest"}, models.Tag{Name: "desert"}
+	for _, record := range []interface{}{&fantasy, &scifi, &romance, &dragons, &quest, &desert} {
+		if err := initializers.DB.Create(record).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, novel := range []models.Novel{
+		{Title: "The Hobbit", Genres: []models.Genre{fantasy}, Tags: []models.Tag{dragons, quest}},
+		{Title: "Dune", Genres: []models.Genre{scifi}, Tags: []models.Tag{desert}},
+		{Title: "Tehanu", Genres: []models.Genre{fantasy, romance}, Tags: []models.Tag{dragons}},
+	} {
+		if err := initializers.DB.Create(&novel).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cases := []struct {
+		query string
+		want  []string
+	}{
+		{"genre=fantasy", []string{"Tehanu", "The Hobbit"}},
+		{"genre=Fantasy,science-fiction", []string{"Dune", "Tehanu", "The Hobbit"}},
+		{"genre=fantasy,romance&genre_mode=all", []string{"Tehanu"}},
+		{"genre=fantasy,science-fiction&genre_mode=all", []string{}},
+		{"exclude_genre=romance", []string{"Dune", "The Hobbit"}},
+		{"tag=dragons,quest&tag_mode=all", []string{"The Hobbit"}},
+		{"tag=quest,desert", []string{"Dune", "The Hobbit"}},
+		{"genre=fantasy&exclude_tag=quest", []string{"Tehanu"}},
+		{"genre=unknown", []string{}},
+	}
+	for _, tc := range cases {
+		titles, total := listNovelTitles(t, tc.query)
+		if !reflect.DeepEqual(titles, tc.want) {
+			t.Errorf("%s: got %v, want %v", tc.query, titles, tc.want)
+		}
+		if total != int64(len(tc.want)) {
+			t.Errorf("%s: total %d, want %d", tc.query, total, len(tc.want))
+		}
+	}
+}
@@ -0,0 +1,253 @@
+package controllers
+
+import (
+	"net/http"
+	"reflect"
+	"sort"
+
+	"github.com/Algoritma-dan-Pemrograman-ITS/Framework-Programming-GIN-GORM/initializers"
+	"github.com/Algoritma-dan-Pemrograman-ITS/Framework-Programming-GIN-GORM/models"
+	"github.com/Algoritma-dan-Pemrograman-ITS/Framework-Programming-GIN-GORM/utils"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Novel fields tracked in models.NovelRevision, in the order they are compared
+var novelRevisionFields = []string{"title", "author", "language", "year_published", "genres", "tags"}
+
+var historySortFields = map[string]string{"created": "id"}
+
+type revisionAuthor struct {
+	ID   uint   `json:"id"`
+	Name string `json:"name"`
+}
+
+type revisionResponse struct {
+	models.NovelRevision
+	ChangedBy *revisionAuthor `json:"changed_by"` // null once the user is deleted
+}
+
+// novelSnapshot returns the tracked fields of a novel loaded with its genres and tags
+func novelSnapshot(novel models.Novel) map[string]interface{} {
+	genres := make([]string, 0, len(novel.Genres))
+	for _, genre := range novel.Genres {
+		genres = append(genres, genre.Slug)
+	}
+	tags := make([]string, 0, len(novel.Tags))
+	for _, tag := range novel.Tags {
+		tags = append(tags, tag.Name)
+	}
+	sort.Strings(genres)
+	sort.Strings(tags)
+
+	return map[string]interface{}{
+		"title":          novel.Title,
+		"author":         novel.Author,
+		"language":       novel.Language,
+		"year_published": novel.YearPublished,
+		"genres":         genres,
+		"tags":           tags,
+	}
+}
+
+func loadNovelSnapshot(tx *gorm.DB, novelID uint) (map[string]interface{}, error) {
+	var novel models.Novel
+	if err := tx.Preload("Genres").Preload("Tags").First(&novel, novelID).Error; err != nil {
+		return nil, err
+	}
+	return novelSnapshot(novel), nil
+}
+
+// recordNovelRevision compares the novel with before (nil for a new novel) and stores the changed
+// fields as revision. Nothing is stored when nothing changed.
+func recordNovelRevision(tx *gorm.DB, before map[string]interface{}, revision models.NovelRevision) error {
+	after, err := loadNovelSnapshot(tx, revision.NovelID)
+	if err != nil {
+		return err
+	}
+
+	changes := models.RevisionChanges{}
+	for _, field := range novelRevisionFields {
+		var old interface{}
+		if before != nil {
+			old = before[field]
+			if reflect.DeepEqual(old, after[field]) {
+				continue
+			}
+		}
+		changes[field] = models.FieldChange{Old: old, New: after[field]}
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+
+	revision.Changes = changes
+	return tx.Create(&revision).Error
+}
+
+// actingUserID is the logged in user making a change, nil when there is none
+func actingUserID(c *gin.Context) *uint {
+	value, _ := c.Get("user")
+	if user, ok := value.(models.User); ok {
+		return &user.ID
+	}
+	return nil
+}
+
+// GetNovelHistory lists the revisions of a novel, newest first.
+// Example: localhost:8001/novels/1/history?page=1&page_size=20
+func GetNovelHistory(c *gin.Context) {
+	var novel models.Novel
+	if result := initializers.DB.First(&novel, c.Param("id")); result.Error != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Novel not found"})
+		return
+	}
+
+	pagination, errMsg := utils.ParsePagination(c, historySortFields, "-created")
+	if errMsg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errMsg})
+		return
+	}
+
+	query := initializers.DB.Model(&models.NovelRevision{}).Where("novel_id = ?", novel.ID)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	var revisions []models.NovelRevision
+	if err := pagination.Apply(query, "novel_revisions").Preload("User").Find(&revisions).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	history := make([]revisionResponse, 0, len(revisions))
+	for _, revision := range revisions {
+		entry := revisionResponse{NovelRevision: revision}
+		if revision.User != nil {
+			entry.ChangedBy = &revisionAuthor{ID: revision.User.ID, Name: revision.User.Name}
+		}
+		history = append(history, entry)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"revisions":  history,
+		"pagination": pagination.Info(c, total),
+	})
+}
+
+// RevertNovel puts the novel's metadata back to how it was right after the chosen revision.
+// The revert is recorded as a new revision, so it can be reverted as well. Genres deleted since are skipped.
+func RevertNovel(c *gin.Context) {
+	var novel models.Novel
+	if result := initializers.DB.First(&novel, c.Param("id")); result.Error != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Novel not found"})
+		return
+	}
+
+	var target models.NovelRevision
+	if result := initializers.DB.Where("id = ? AND novel_id = ?", c.Param("revision"), novel.ID).First(&target); result.Error != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Revision not found"})
+		return
+	}
+
+	// Replay the revisions up to the target. Novels created before revisions were recorded have no
+	// create revision, so a field the replay never set takes the old value of the first later change to it.
+	// Fields never recorded at all keep their current value.
+	var revisions []models.NovelRevision
+	if err := initializers.DB.Where("novel_id = ?", novel.ID).Order("id").Find(&revisions).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	state := map[string]interface{}{}
+	for _, revision := range revisions {
+		for field, change := range revision.Changes {
+			if revision.ID <= target.ID {
+				state[field] = change.New
+			} else if _, ok := state[field]; !ok {
+				state[field] = change.Old
+			}
+		}
+	}
+
+	err := initializers.DB.Transaction(func(tx *gorm.DB) error {
+		before, err := loadNovelSnapshot(tx, novel.ID)
+		if err != nil {
+			return err
+		}
+
+		updates := map[string]interface{}{}
+		for _, field := range []string{"title", "author", "language"} {
+			if value, ok := state[field].(string); ok {
+				updates[field] = value
+			}
+		}
+		// Numbers come back from the JSON column as float64
+		if year, ok := state["year_published"].(float64); ok {
+			updates["year_published"] = int(year)
+		}
+		if len(updates) > 0 {
+			if err := tx.Model(&novel).Updates(updates).Error; err != nil {
+				return err
+			}
+		}
+
+		if value, ok := state["genres"]; ok {
+			genres := []models.Genre{}
+			if slugs := stringList(value); len(slugs) > 0 {
+				if err := tx.Where("slug IN ?", slugs).Find(&genres).Error; err != nil {
+					return err
+				}
+			}
+			if err := replaceAssociation(tx, &novel, "Genres", genres, len(genres)); err != nil {
+				return err
+			}
+		}
+		if value, ok := state["tags"]; ok {
+			tags, err := findOrCreateTags(tx, stringList(value))
+			if err != nil {
+				return err
+			}
+			if err := replaceAssociation(tx, &novel, "Tags", tags, len(tags)); err != nil {
+				return err
+			}
+		}
+
+		return recordNovelRevision(tx, before, models.NovelRevision{
+			NovelID:  novel.ID,
+			UserID:   actingUserID(c),
+			Action:   models.RevisionRevert,
+			RevertOf: &target.ID,
+		})
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	initializers.DB.Preload("Genres").Preload("Tags").First(&novel, novel.ID)
+	indexNovels(novel)
+	c.JSON(http.StatusOK, gin.H{"novel": novel})
+}
+
+// replaceAssociation replaces a many-to-many association, clearing it when values is empty
+func replaceAssociation(tx *gorm.DB, novel *models.Novel, name string, values interface{}, count int) error {
+	if count == 0 {
+		return tx.Model(novel).Association(name).Clear()
+	}
+	return tx.Model(novel).Association(name).Replace(values)
+}
+
+// stringList converts a list decoded from JSON ([]interface{} of strings)
+func stringList(value interface{}) []string {
+	items, _ := value.([]interface{})
+	list := make([]string, 0, len(items))
+	for _, item := range items {
+		if s, ok := item.(string); ok {
+			list = append(list, s)
+		}
+	}
+	return list
+}
@@ -0,0 +1,169 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/Algoritma-dan-Pemrograman-ITS/Framework-Programming-GIN-GORM/initializers"
+	"github.com/Algoritma-dan-Pemrograman-ITS/Framework-Programming-GIN-GORM/models"
+	"github.com/gin-gonic/gin"
+)
+
+// callNovelHandler runs handler as editor with a JSON body and the given route params
+func callNovelHandler(t *testing.T, editor models.User, handler gin.HandlerFunc, method, body string, params gin.Params) *httptest.ResponseRecorder {
+	t.Helper()
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(method, "/", strings.NewReader(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Params = params
+	c.Set("user", editor)
+	handler(c)
+	return w
+}
+
+func novelRevisions(t *testing.T, novelID uint) []models.NovelRevision {
+	t.Helper()
+	var revisions []models.NovelRevision
+	if err := initializers.DB.Where("novel_id = ?", novelID).Order("id").Find(&revisions).Error; err != nil {
+		t.Fatal(err)
+	}
+	return revisions
+}
+
+func TestRevertNovelReplaysRevisions(t *testing.T) {
+	setupTestDB(t)
+	editor := models.User{Name: "Editor", Email: "editor@example.com", Role: models.RoleEditor}
+	if err := initializers.DB.Create(&editor).Error; err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"Fantasy", "Adventure"} {
+		if err := initializers.DB.Create(&models.Genre{Name: name, Slug: strings.ToLower(name)}).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	w := callNovelHandler(t, editor, CreateNovel, http.MethodPost,
+		`{"title":"The Hobit","author":"Tolkien","language":"english","year_published":1937,"genre_ids":[1],"tags":["dragons"]}`, nil)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create: status %d: %s", w.Code, w.Body)
+	}
+	var created struct {
+		Novel models.Novel `json:"novel"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &created)
+	id := strconv.Itoa(int(created.Novel.ID))
+	params := gin.Params{{Key: "id", Value: id}}
+
+	// Revision 2 fixes the title only, revision 3 changes author, genres and tags
+	for _, body := range []string{
+		`{"title":"The Hobbit"}`,
+		`{"author":"J.R.R. Tolkien","genre_ids":[1,2],"tags":["dragons","quest"]}`,
+	} {
+		if w := callNovelHandler(t, editor, UpdateNovel, http.MethodPut, body, params); w.Code != http.StatusOK {
+			t.Fatalf("update %s: status %d: %s", body, w.Code, w.Body)
+		}
+	}
+	revisions := novelRevisions(t, created.Novel.ID)
+	if len(revisions) != 3 {
+		t.Fatalf("got %d revisions, want 3", len(revisions))
+	}
+
+	// Back to right after revision 2: its title plus everything revision 1 set
+	revert := func(revision models.NovelRevision) {
+		t.Helper()
+		p := append(params, gin.Param{Key: "revision", Value: strconv.Itoa(int(revision.ID))})
+		if w := callNovelHandler(t, editor, RevertNovel, http.MethodPost, "", p); w.Code != http.StatusOK {
+			t.Fatalf("revert to %d: status %d: %s", revision.ID, w.Code, w.Body)
+		}
+	}
+	revert(revisions[1])
+
+	snapshot, err := loadNovelSnapshot(initializers.DB, created.Novel.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{
+		"title":          "The Hobbit",
+		"author":         "Tolkien",
+		"language":       "english",
+		"year_published": 1937,
+		"genres":         []string{"fantasy"},
+		"tags":           []string{"dragons"},
+	}
+	if !reflect.DeepEqual(snapshot, want) {
+		t.Errorf("after revert got %v, want %v", snapshot, want)
+	}
+
+	// The revert is a revision of its own, pointing at its target, and can be undone in turn
+	revisions = novelRevisions(t, created.Novel.ID)
+	last := revisions[len(revisions)-1]
+	if last.Action != models.RevisionRevert || last.RevertOf == nil || *last.RevertOf != revisions[1].ID {
+		t.Errorf("last revision is %s reverting %v, want a revert of %d", last.Action, last.RevertOf, revisions[1].ID)
+	}
+	if _, ok := last.Changes["language"]; ok {
+		t.Error("revert recorded an unchanged field")
+	}
+
+	revert(revisions[2])
+	snapshot, _ = loadNovelSnapshot(initializers.DB, created.Novel.ID)
+	if snapshot["author"] != "J.R.R. Tolkien" || !reflect.DeepEqual(snapshot["genres"], []string{"adventure", "fantasy"}) {
+		t.Errorf("after reverting the revert got %v", snapshot)
+	}
+}
+
+func TestRevertNovelUnknownRevision(t *testing.T) {
+	setupTestDB(t)
+	editor := models.User{Name: "Editor", Email: "editor@example.com", Role: models.RoleEditor}
+	initializers.DB.Create(&editor)
+	novel := models.Novel{Title: "Dune", Author: "Frank Herbert"}
+	other := models.Novel{Title: "Emma", Author: "Jane Austen"}
+	initializers.DB.Create(&novel)
+	initializers.DB.Create(&other)
+	revision := models.NovelRevision{NovelID: other.ID, Action: models.RevisionCreate, Changes: models.RevisionChanges{}}
+	if err := initializers.DB.Create(&revision).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	// A revision of another novel is not found through this one
+	params := gin.Params{{Key: "id", Value: strconv.Itoa(int(novel.ID))}, {Key: "revision", Value: strconv.Itoa(int(revision.ID))}}
+	if w := callNovelHandler(t, editor, RevertNovel, http.MethodPost, "", params); w.Code != http.StatusNotFound {
+		t.Errorf("status %d, want 404", w.Code)
+	}
+}
+
+func TestRevertNovelWithoutCreateRevision(t *testing.T) {
+	setupTestDB(t)
+	editor := models.User{Name: "Editor", Email: "editor@example.com", Role: models.RoleEditor}
+	initializers.DB.Create(&editor)
+
+	// Added before revisions were recorded, so its history starts with an update
+	novel := models.Novel{Title: "The Hobit", Author: "Tolkien", Language: "english", YearPublished: 1937}
+	if err := initializers.DB.Create(&novel).Error; err != nil {
+		t.Fatal(err)
+	}
+	params := gin.Params{{Key: "id", Value: strconv.Itoa(int(novel.ID))}}
+	for _, body := range []string{`{"title":"The Hobbit"}`, `{"author":"J.R.R. Tolkien"}`} {
+		if w := callNovelHandler(t, editor, UpdateNovel, http.MethodPut, body, params); w.Code != http.StatusOK {
+			t.Fatalf("update %s: status %d: %s", body, w.Code, w.Body)
+		}
+	}
+
+	// Right after the first update the author was still the original one
+	first := novelRevisions(t, novel.ID)[0]
+	p := append(params, gin.Param{Key: "revision", Value: strconv.Itoa(int(first.ID))})
+	if w := callNovelHandler(t, editor, RevertNovel, http.MethodPost, "", p); w.Code != http.StatusOK {
+		t.Fatalf("revert: status %d: %s", w.Code, w.Body)
+	}
+
+	var reverted models.Novel
+	initializers.DB.First(&reverted, novel.ID)
+	if reverted.Title != "The Hobbit" || reverted.Author != "Tolkien" || reverted.YearPublished != 1937 {
+		t.Errorf("after revert got %q by %q (%d), want The Hobbit by Tolkien (1937)", reverted.Title, reverted.Author, reverted.YearPublished)
+	}
+}
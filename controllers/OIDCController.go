@@ -0,0 +1,243 @@
+package controllers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Algoritma-dan-Pemrograman-ITS/Framework-Programming-GIN-GORM/initializers"
+	"github.com/Algoritma-dan-Pemrograman-ITS/Framework-Programming-GIN-GORM/models"
+	"github.com/Algoritma-dan-Pemrograman-ITS/Framework-Programming-GIN-GORM/oidc"
+	"github.com/Algoritma-dan-Pemrograman-ITS/Framework-Programming-GIN-GORM/utils"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	oidcStatePurpose = "oidc_state"
+	oidcStateCookie  = "oidc_state"
+	oidcStateTTL     = 10 * time.Minute
+)
+
+var errOIDCEmailNotVerified = errors.New("The provider did not confirm this email address")
+
+func oidcRedirectURI(provider string) string {
+	return utils.APIURL() + "/auth/oidc/" + provider + "/callback"
+}
+
+// GetOIDCProviders lists the configured external login providers (for login buttons)
+func GetOIDCProviders(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"providers": oidc.Names()})
+}
+
+// StartOIDCLogin redirects the browser to the provider.
+// Optional ?return_to=/path sends the browser back to the frontend (APP_URL) after login instead of answering JSON.
+func StartOIDCLogin(c *gin.Context) {
+	provider, ok := oidc.Get(c.Param("provider"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Unknown login provider"})
+		return
+	}
+
+	// Only paths on our own frontend, never another site
+	returnTo := c.Query("return_to")
+	if returnTo != "" && (!strings.HasPrefix(returnTo, "/") || strings.HasPrefix(returnTo, "//")) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "return_to must be a path like /library"})
+		return
+	}
+
+	state, err1 := utils.RandomToken(16)
+	nonce, err2 := utils.RandomToken(16)
+	verifier, err3 := utils.RandomToken(32)
+	if err1 != nil || err2 != nil || err3 != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not start login"})
+		return
+	}
+
+	// The flow state lives in a short-lived signed cookie, so nothing is stored server side
+	stateToken, err := utils.GenerateSignedToken(oidcStatePurpose, 0, map[string]interface{}{
+		"provider":  provider.Name,
+		"state":     state,
+		"nonce":     nonce,
+		"verifier":  verifier,
+		"return_to": returnTo,
+	}, oidcStateTTL)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not start login"})
+		return
+	}
+
+	authURL, err := provider.AuthCodeURL(c.Request.Context(), oidcRedirectURI(provider.Name), state, nonce, verifier)
+	if err != nil {
+		log.Printf("oidc: %v", err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Login provider is unavailable"})
+		return
+	}
+
+	// Always Lax: with Strict the browser would not send it back on the redirect from the provider
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oidcStateCookie, stateToken, int(oidcStateTTL.Seconds()), "/auth/oidc", "", utils.CookieSecure(), true)
+	c.Redirect(http.StatusFound, authURL)
+}
+
+// OIDCCallback finishes the login started by StartOIDCLogin and answers like Login
+// (or redirects to return_to with the auth cookies set)
+func OIDCCallback(c *gin.Context) {
+	provider, ok := oidc.Get(c.Param("provider"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Unknown login provider"})
+		return
+	}
+
+	stateToken, _ := c.Cookie(oidcStateCookie)
+	c.SetCookie(oidcStateCookie, "", -1, "/auth/oidc", "", utils.CookieSecure(), true)
+
+	flow, err := utils.ParseSignedToken(stateToken, oidcStatePurpose)
+	if err != nil || flow["provider"] != provider.Name || flow["state"] != c.Query("state") || c.Query("state") == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Login session expired or invalid, please try again"})
+		return
+	}
+
+	if providerErr := c.Query("error"); providerErr != "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Login was cancelled or denied: " + providerErr})
+		return
+	}
+
+	nonce, _ := flow["nonce"].(string)
+	verifier, _ := flow["verifier"].(string)
+	returnTo, _ := flow["return_to"].(string)
+
+	identity, err := provider.Exchange(c.Request.Context(), oidcRedirectURI(provider.Name), c.Query("code"), verifier, nonce)
+	if err != nil {
+		log.Printf("oidc %s: %v", provider.Name, err)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Could not verify the login with " + provider.Name})
+		return
+	}
+
+	user, err := userForIdentity(provider.Name, identity)
+	if err == errOIDCEmailNotVerified {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not link account"})
+		return
+	}
+
+	if user.IsSuspended() {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Account suspended"})
+		return
+	}
+
+	// The second factor is still required, exactly like a password login
+	if user.TOTPEnabled {
+		mfaToken, err := utils.GenerateSignedToken(mfaPendingPurpose, user.ID, nil, mfaPendingTTL)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not create token"})
+			return
+		}
+		if returnTo != "" {
+			c.Redirect(http.StatusFound, utils.AppURL()+returnTo+"#mfa_token="+url.QueryEscape(mfaToken))
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"mfa_required": true,
+			"mfa_token":    mfaToken,
+			"expires_in":   int(mfaPendingTTL.Seconds()),
+		})
+		return
+	}
+
+	if returnTo != "" {
+		if _, err := startSession(c, *user); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not create token"})
+			return
+		}
+		c.Redirect(http.StatusFound, utils.AppURL()+returnTo)
+		return
+	}
+
+	completeLogin(c, *user)
+}
+
+// userForIdentity finds the user linked to an external identity, linking or creating one by verified email.
+// An existing account whose email was never verified locally is taken over by the identity: its password is
+// replaced, its sessions and API keys revoked and its 2FA reset, so whoever registered the address first
+// cannot keep access to it or stand in front of the real owner with a second factor of their own.
+func userForIdentity(provider string, identity *oidc.Identity) (*models.User, error) {
+	var link models.UserIdentity
+	err := initializers.DB.Where("provider = ? AND subject = ?", provider, identity.Subject).First(&link).Error
+	if err == nil {
+		var user models.User
+		if err := initializers.DB.First(&user, link.UserID).Error; err != nil {
+			return nil, err
+		}
+		return &user, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	if identity.Email == "" || !identity.EmailVerified {
+		return nil, errOIDCEmailNotVerified
+	}
+
+	var user models.User
+	err = initializers.DB.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		result := tx.Where("email = ?", identity.Email).First(&user)
+		switch {
+		case result.Error == nil && !user.EmailVerified:
+			// Random hash that no password matches, a password can be set again with /password/forgot
+			unusable, err := utils.RandomToken(32)
+			if err != nil {
+				return err
+			}
+			if err := tx.Model(&user).Updates(map[string]interface{}{
+				"password":          unusable,
+				"email_verified":    true,
+				"email_verified_at": now,
+				"totp_enabled":      false,
+				"totp_secret":       "",
+				"totp_last_step":    0,
+			}).Error; err != nil {
+				return err
+			}
+			if err := tx.Where("user_id = ?", user.ID).Delete(&models.RecoveryCode{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&models.APIKey{}).Where("user_id = ? AND revoked_at IS NULL", user.ID).
+				Update("revoked_at", now).Error; err != nil {
+				return err
+			}
+			if err := revokeAllFamilies(tx, user.ID); err != nil {
+				return err
+			}
+		case errors.Is(result.Error, gorm.ErrRecordNotFound):
+			name := identity.Name
+			if len(name) < 2 {
+				name = strings.SplitN(identity.Email, "@", 2)[0]
+			}
+			user = models.User{Name: name, Email: identity.Email, EmailVerified: true, EmailVerifiedAt: &now}
+			if err := tx.Create(&user).Error; err != nil {
+				return err
+			}
+		case result.Error != nil:
+			return result.Error
+		}
+
+		return tx.Create(&models.UserIdentity{
+			UserID:   user.ID,
+			Provider: provider,
+			Subject:  identity.Subject,
+			Email:    identity.Email,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
@@ -0,0 +1,71 @@
+package controllers
+
+import (
+	"testing"
+
+	"github.com/Algoritma-dan-Pemrograman-ITS/Framework-Programming-GIN-GORM/initializers"
+	"github.com/Algoritma-dan-Pemrograman-ITS/Framework-Programming-GIN-GORM/models"
+	"github.com/Algoritma-dan-Pemrograman-ITS/Framework-Programming-GIN-GORM/oidc"
+)
+
+func TestUserForIdentityTakesOverUnverifiedAccount(t *testing.T) {
+	setupTestDB(t)
+	t.Setenv("JWT_SECRET", "test-secret")
+
+	// Someone registered the address without verifying it, then set up 2FA and an API key
+	squatter, _ := loginCookies(t)
+	initializers.DB.Model(&squatter).Updates(map[string]interface{}{
+		"password":     "squatter-hash",
+		"totp_enabled": true,
+		"totp_secret":  "JBSWY3DPEHPK3PXP",
+	})
+	initializers.DB.Create(&models.RecoveryCode{UserID: squatter.ID, CodeHash: "code-hash"})
+	initializers.DB.Create(&models.APIKey{UserID: squatter.ID, Name: "script", KeyHash: "key-hash"})
+
+	user, err := userForIdentity("google", &oidc.Identity{Subject: "g-1", Email: squatter.Email, EmailVerified: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.ID != squatter.ID {
+		t.Fatalf("got user %d, want the existing account %d", user.ID, squatter.ID)
+	}
+
+	var owner models.User
+	initializers.DB.First(&owner, squatter.ID)
+	if !owner.EmailVerified || owner.Password == "squatter-hash" {
+		t.Error("password kept or email left unverified")
+	}
+	if owner.TOTPEnabled || owner.TOTPSecret != "" {
+		t.Error("squatter's 2FA still active")
+	}
+
+	var codes, keys int64
+	initializers.DB.Model(&models.RecoveryCode{}).Where("user_id = ?", owner.ID).Count(&codes)
+	initializers.DB.Model(&models.APIKey{}).Where("user_id = ? AND revoked_at IS NULL", owner.ID).Count(&keys)
+	if codes != 0 || keys != 0 {
+		t.Errorf("got %d recovery codes and %d active API keys, want none", codes, keys)
+	}
+	if activeSessions(t, owner.ID) != 0 {
+		t.Error("squatter's session still active")
+	}
+}
+
+func TestUserForIdentityKeepsVerifiedAccount(t *testing.T) {
+	setupTestDB(t)
+
+	existing := models.User{Name: "Alice", Email: "alice@example.com", Password: "hash", EmailVerified: true, TOTPEnabled: true}
+	initializers.DB.Create(&existing)
+	initializers.DB.Create(&models.APIKey{UserID: existing.ID, Name: "script", KeyHash: "key-hash"})
+
+	if _, err := userForIdentity("google", &oidc.Identity{Subject: "g-1", Email: existing.Email, EmailVerified: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	var user models.User
+	initializers.DB.First(&user, existing.ID)
+	var keys int64
+	initializers.DB.Model(&models.APIKey{}).Where("user_id = ? AND revoked_at IS NULL", user.ID).Count(&keys)
+	if user.Password != "hash" || !user.TOTPEnabled || keys != 1 {
+		t.Error("linking a verified account changed its credentials")
+	}
+}
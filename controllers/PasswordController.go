@@ -0,0 +1,132 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Algoritma-dan-Pemrograman-ITS/Framework-Programming-GIN-GORM/initializers"
+	"github.com/Algoritma-dan-Pemrograman-ITS/Framework-Programming-GIN-GORM/mailer"
+	"github.com/Algoritma-dan-Pemrograman-ITS/Framework-Programming-GIN-GORM/models"
+	"github.com/Algoritma-dan-Pemrograman-ITS/Framework-Programming-GIN-GORM/utils"
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+const defaultPasswordResetTTL = time.Hour
+
+var errTokenAlreadyUsed = errors.New("token already used")
+
+// ForgotPassword emails a single-use reset link. The response is the same whether or not
+// the email is registered, so it cannot be used to discover accounts.
+// Use Raw JSON POST with param: "email"
+func ForgotPassword(c *gin.Context) {
+	var body struct {
+		Email string `json:"email" binding:"required,email"`
+	}
+
+	if err := c.BindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	response := gin.H{"message": "If that email is registered, a password reset link has been sent"}
+
+	var user models.User
+	if result := initializers.DB.First(&user, "email = ?", strings.TrimSpace(body.Email)); result.Error != nil {
+		c.JSON(http.StatusOK, response)
+		return
+	}
+
+	rawToken, err := utils.RandomToken(32)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not create reset token"})
+		return
+	}
+
+	ttl := utils.DurationFromEnv("PASSWORD_RESET_TTL", defaultPasswordResetTTL)
+	err = initializers.DB.Transaction(func(tx *gorm.DB) error {
+		// Only the latest link works
+		if err := tx.Model(&models.PasswordResetToken{}).
+			Where("user_id = ? AND used_at IS NULL", user.ID).
+			Update("used_at", time.Now()).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.PasswordResetToken{
+			UserID:    user.ID,
+			TokenHash: utils.HashToken(rawToken),
+			ExpiresAt: time.Now().Add(ttl),
+		}).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not create reset token"})
+		return
+	}
+
+	link := utils.AppURL() + "/reset-password?token=" + url.QueryEscape(rawToken)
+	mailer.SendAsync(user.Email, "Reset your password",
+		"Hi "+user.Name+",\n\n"+
+			"Someone asked to reset the password of your account. Open this link to choose a new one:\n\n"+
+			link+"\n\n"+
+			"The link expires in "+ttl.String()+" and can only be used once. If you did not ask for it, ignore this email.")
+
+	c.JSON(http.StatusOK, response)
+}
+
+// ResetPassword sets a new password using a token from ForgotPassword and logs out every session.
+// Use Raw JSON POST with param: "token", "password"
+func ResetPassword(c *gin.Context) {
+	var body struct {
+		Token    string `json:"token" binding:"required"`
+		Password string `json:"password" binding:"required,min=8"`
+	}
+
+	if err := c.BindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var resetToken models.PasswordResetToken
+	result := initializers.DB.First(&resetToken, "token_hash = ?", utils.HashToken(body.Token))
+	if result.Error != nil || resetToken.UsedAt != nil || time.Now().After(resetToken.ExpiresAt) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired reset token"})
+		return
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(body.Password), bcrypt.DefaultCost)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not hash password"})
+		return
+	}
+
+	err = initializers.DB.Transaction(func(tx *gorm.DB) error {
+		// Claim the token first so two concurrent resets cannot both succeed
+		claim := tx.Model(&models.PasswordResetToken{}).
+			Where("id = ? AND used_at IS NULL", resetToken.ID).
+			Update("used_at", time.Now())
+		if claim.Error != nil {
+			return claim.Error
+		}
+		if claim.RowsAffected == 0 {
+			return errTokenAlreadyUsed
+		}
+
+		if err := tx.Model(&models.User{}).Where("id = ?", resetToken.UserID).Update("password", string(hashedPassword)).Error; err != nil {
+			return err
+		}
+		return revokeAllFamilies(tx, resetToken.UserID)
+	})
+	if err == errTokenAlreadyUsed {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired reset token"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not reset password"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password has been reset, please login again"})
+}
@@ -0,0 +1,108 @@
+package controllers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Algoritma-dan-Pemrograman-ITS/Framework-Programming-GIN-GORM/initializers"
+	"github.com/Algoritma-dan-Pemrograman-ITS/Framework-Programming-GIN-GORM/mailer"
+	"github.com/Algoritma-dan-Pemrograman-ITS/Framework-Programming-GIN-GORM/models"
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// captureMailer hands every email body to the test
+type captureMailer chan string
+
+func (m captureMailer) Send(to, subject, body string) error {
+	m <- body
+	return nil
+}
+
+var resetLink = regexp.MustCompile(`/reset-password\?token=(\S+)`)
+
+// requestPasswordReset calls POST /password/forgot and returns the token from the emailed link
+func requestPasswordReset(t *testing.T, mails captureMailer, email string) string {
+	t.Helper()
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/password/forgot", strings.NewReader(`{"email":"`+email+`"}`))
+	ForgotPassword(c)
+	if w.Code != http.StatusOK {
+		t.Fatalf("forgot password: status %d: %s", w.Code, w.Body)
+	}
+
+	select {
+	case body := <-mails:
+		match := resetLink.FindStringSubmatch(body)
+		if match == nil {
+			t.Fatalf("no reset link in %q", body)
+		}
+		token, _ := url.QueryUnescape(match[1])
+		return token
+	case <-time.After(time.Second):
+		t.Fatal("no reset email sent")
+	}
+	return ""
+}
+
+func resetPassword(token, password string) int {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/password/reset", strings.NewReader(`{"token":"`+token+`","password":"`+password+`"}`))
+	ResetPassword(c)
+	return w.Code
+}
+
+func TestPasswordResetTokenIsSingleUse(t *testing.T) {
+	setupTestDB(t)
+	t.Setenv("JWT_SECRET", "test-secret")
+	mails := make(captureMailer, 1)
+	mailer.Set(mails)
+	t.Cleanup(func() { mailer.Set(nil) })
+
+	user, _ := loginCookies(t)
+	token := requestPasswordReset(t, mails, user.Email)
+
+	if code := resetPassword(token, "new-password-1"); code != http.StatusOK {
+		t.Fatalf("first reset: status %d, want 200", code)
+	}
+	if code := resetPassword(token, "new-password-2"); code != http.StatusBadRequest {
+		t.Errorf("second reset with the same token: status %d, want 400", code)
+	}
+
+	initializers.DB.First(&user, user.ID)
+	if bcrypt.CompareHashAndPassword([]byte(user.Password), []byte("new-password-1")) != nil {
+		t.Error("password is not the one from the first reset")
+	}
+	if activeSessions(t, user.ID) != 0 {
+		t.Error("sessions still active after a password reset")
+	}
+}
+
+func TestPasswordResetOnlyLatestLinkWorks(t *testing.T) {
+	setupTestDB(t)
+	mails := make(captureMailer, 1)
+	mailer.Set(mails)
+	t.Cleanup(func() { mailer.Set(nil) })
+
+	user := models.User{Name: "Alice", Email: "alice@example.com"}
+	initializers.DB.Create(&user)
+
+	first := requestPasswordReset(t, mails, user.Email)
+	second := requestPasswordReset(t, mails, user.Email)
+	if code := resetPassword(first, "new-password-1"); code != http.StatusBadRequest {
+		t.Errorf("superseded link: status %d, want 400", code)
+	}
+
+	// An expired link is refused too
+	initializers.DB.Model(&models.PasswordResetToken{}).Where("used_at IS NULL").Update("expires_at", time.Now().Add(-time.Minute))
+	if code := resetPassword(second, "new-password-1"); code != http.StatusBadRequest {
+		t.Errorf("expired link: status %d, want 400", code)
+	}
+}
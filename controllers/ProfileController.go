@@ -0,0 +1,258 @@
+package controllers
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Algoritma-dan-Pemrograman-ITS/Framework-Programming-GIN-GORM/initializers"
+	"github.com/Algoritma-dan-Pemrograman-ITS/Framework-Programming-GIN-GORM/mailer"
+	"github.com/Algoritma-dan-Pemrograman-ITS/Framework-Programming-GIN-GORM/models"
+	"github.com/Algoritma-dan-Pemrograman-ITS/Framework-Programming-GIN-GORM/utils"
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+const (
+	changeEmailPurpose    = "change_email"
+	defaultChangeEmailTTL = 24 * time.Hour
+)
+
+// UpdateProfile changes the public profile of the logged in user. Body (all optional): "name", "bio", "avatar_url"
+func UpdateProfile(c *gin.Context) {
+	var body struct {
+		Name      *string `json:"name" binding:"omitempty,min=2,max=255"`
+		Bio       *string `json:"bio" binding:"omitempty,max=1000"`
+		AvatarURL *string `json:"avatar_url" binding:"omitempty,max=512"`
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, ok := getUserFromContext(c)
+	if !ok {
+		return
+	}
+
+	updates := map[string]interface{}{}
+	if body.Name != nil {
+		name := strings.TrimSpace(*body.Name)
+		if utf8.RuneCountInString(name) < 2 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "name must be at least 2 characters"})
+			return
+		}
+		updates["name"] = name
+	}
+	if body.Bio != nil {
+		updates["bio"] = *body.Bio
+	}
+	if body.AvatarURL != nil {
+		// Empty removes the avatar; anything else must be an absolute http(s) URL
+		if *body.AvatarURL != "" {
+			parsed, err := url.Parse(*body.AvatarURL)
+			if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "avatar_url must be an http or https URL"})
+				return
+			}
+		}
+		updates["avatar_url"] = *body.AvatarURL
+	}
+
+	if len(updates) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Nothing to update, expected name, bio or avatar_url"})
+		return
+	}
+
+	if result := initializers.DB.Model(user).Updates(updates); result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not update profile"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"user": gin.H{
+		"id":         user.ID,
+		"name":       user.Name,
+		"email":      user.Email,
+		"bio":        user.Bio,
+		"avatar_url": user.AvatarURL,
+	}})
+}
+
+// RequestEmailChange mails a confirmation link to the new address; the email only changes once it is opened.
+// Body: "email", "password"
+func RequestEmailChange(c *gin.Context) {
+	var body struct {
+		Email    string `json:"email" binding:"required,email"`
+		Password string `json:"password" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, ok := getUserFromContext(c)
+	if !ok {
+		return
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(body.Password)); err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Current password is incorrect"})
+		return
+	}
+
+	newEmail := strings.TrimSpace(body.Email)
+	if strings.EqualFold(newEmail, user.Email) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "This is already your email address"})
+		return
+	}
+
+	var count int64
+	initializers.DB.Model(&models.User{}).Where("email = ?", newEmail).Count(&count)
+	if count > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Email is already in use"})
+		return
+	}
+
+	// Bound to the current address and the request time, so a link stops working once any email change went through
+	ttl := utils.DurationFromEnv("EMAIL_CHANGE_TTL", defaultChangeEmailTTL)
+	extra := map[string]interface{}{"email": user.Email, "new_email": newEmail, "requested_at": time.Now().UnixMilli()}
+	token, err := utils.GenerateSignedToken(changeEmailPurpose, user.ID, extra, ttl)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not create token"})
+		return
+	}
+
+	// GET /profile/email/confirm answers on the API itself, the frontend has no page for it
+	link := utils.APIURL() + "/profile/email/confirm?token=" + url.QueryEscape(token)
+	mailer.SendAsync(newEmail, "Confirm your new email address",
+		"Hi "+user.Name+",\n\n"+
+			"Open this link to use this address for your account:\n\n"+
+			link+"\n\n"+
+			"The link expires in "+ttl.String()+".")
+	mailer.SendAsync(user.Email, "Email change requested",
+		"Hi "+user.Name+",\n\n"+
+			"Someone asked to change the email of your account to "+newEmail+".\n"+
+			"If this wasn't you, change your password right away.")
+
+	c.JSON(http.StatusOK, gin.H{"message": "Confirmation link sent to " + newEmail})
+}
+
+// ConfirmEmailChange switches the account to the new address from the link.
+// Example: localhost:8001/profile/email/confirm?token=...
+func ConfirmEmailChange(c *gin.Context) {
+	tokenString := c.Query("token")
+	if tokenString == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Token is required"})
+		return
+	}
+
+	claims, err := utils.ParseSignedToken(tokenString, changeEmailPurpose)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired confirmation link"})
+		return
+	}
+
+	sub, _ := claims["sub"].(string)
+	id, err := strconv.ParseUint(sub, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired confirmation link"})
+		return
+	}
+
+	var user models.User
+	if result := initializers.DB.First(&user, id); result.Error != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired confirmation link"})
+		return
+	}
+
+	oldEmail, _ := claims["email"].(string)
+	newEmail, _ := claims["new_email"].(string)
+	if oldEmail != user.Email || newEmail == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired confirmation link"})
+		return
+	}
+
+	// Links are single use: changing back to oldEmail later must not revive this one
+	requestedAt, _ := claims["requested_at"].(float64) // JSON numbers decode as float64
+	if user.EmailChangedAt != nil && int64(requestedAt) <= user.EmailChangedAt.UnixMilli() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired confirmation link"})
+		return
+	}
+
+	// Opening the link proves the new address works, so it counts as verified.
+	// The email is still checked in the WHERE so two links opened at once cannot both apply.
+	now := time.Now()
+	updates := map[string]interface{}{"email": newEmail, "email_verified": true, "email_verified_at": now, "email_changed_at": now}
+	result := initializers.DB.Model(&models.User{}).Where("id = ? AND email = ?", user.ID, oldEmail).Updates(updates)
+	if result.Error != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Email is already in use"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired confirmation link"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Email changed to " + newEmail})
+}
+
+// ChangePassword sets a new password and logs out every other session. Body: "current_password", "new_password"
+func ChangePassword(c *gin.Context) {
+	var body struct {
+		CurrentPassword string `json:"current_password" binding:"required"`
+		NewPassword     string `json:"new_password" binding:"required,min=8"`
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, ok := getUserFromContext(c)
+	if !ok {
+		return
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(body.CurrentPassword)); err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Current password is incorrect"})
+		return
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(body.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not hash password"})
+		return
+	}
+
+	// Keep the session that made the change, revoke the others
+	currentFamily := ""
+	if tokenString, _ := accessTokenFromRequest(c); tokenString != "" {
+		if claims, err := utils.ParseAccessToken(tokenString); err == nil {
+			currentFamily = claims.FamilyID
+		}
+	}
+
+	err = initializers.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(user).Update("password", string(hashedPassword)).Error; err != nil {
+			return err
+		}
+		return revokeOtherFamilies(tx, user.ID, currentFamily)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not change password"})
+		return
+	}
+
+	mailer.SendAsync(user.Email, "Your password was changed",
+		"Hi "+user.Name+",\n\n"+
+			"The password of your account was just changed and your other devices were logged out.\n"+
+			"If this wasn't you, reset your password right away.")
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password changed, other sessions have been logged out"})
+}
@@ -0,0 +1,95 @@
+package controllers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Algoritma-dan-Pemrograman-ITS/Framework-Programming-GIN-GORM/initializers"
+	"github.com/Algoritma-dan-Pemrograman-ITS/Framework-Programming-GIN-GORM/models"
+	"github.com/Algoritma-dan-Pemrograman-ITS/Framework-Programming-GIN-GORM/utils"
+	"github.com/gin-gonic/gin"
+)
+
+func confirmEmailChange(token string) int {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/profile/email/confirm?token="+url.QueryEscape(token), nil)
+	ConfirmEmailChange(c)
+	return w.Code
+}
+
+func emailChangeToken(t *testing.T, user models.User, newEmail string) string {
+	t.Helper()
+	extra := map[string]interface{}{"email": user.Email, "new_email": newEmail, "requested_at": time.Now().UnixMilli()}
+	token, err := utils.GenerateSignedToken(changeEmailPurpose, user.ID, extra, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func TestConfirmEmailChangeIsSingleUse(t *testing.T) {
+	setupTestDB(t)
+	t.Setenv("JWT_SECRET", "test-secret")
+
+	user := models.User{Name: "Alice", Email: "a@example.com"}
+	if err := initializers.DB.Create(&user).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	toB := emailChangeToken(t, user, "b@example.com")
+	if code := confirmEmailChange(toB); code != http.StatusOK {
+		t.Fatalf("first confirmation: status %d, want 200", code)
+	}
+	if code := confirmEmailChange(toB); code != http.StatusBadRequest {
+		t.Errorf("second confirmation: status %d, want 400", code)
+	}
+
+	// Back to the first address with a new link: the old link matches the email again but must stay dead
+	initializers.DB.First(&user, user.ID)
+	time.Sleep(2 * time.Millisecond)
+	if code := confirmEmailChange(emailChangeToken(t, user, "a@example.com")); code != http.StatusOK {
+		t.Fatalf("changing back: status %d, want 200", code)
+	}
+	if code := confirmEmailChange(toB); code != http.StatusBadRequest {
+		t.Errorf("replayed link after changing back: status %d, want 400", code)
+	}
+
+	initializers.DB.First(&user, user.ID)
+	if user.Email != "a@example.com" {
+		t.Errorf("email is %q, want a@example.com", user.Email)
+	}
+}
+
+func TestUpdateProfileRejectsBlankName(t *testing.T) {
+	setupTestDB(t)
+	user := models.User{Name: "Alice", Email: "a@example.com"}
+	if err := initializers.DB.Create(&user).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	for body, want := range map[string]int{
+		`{"name":"   "}`:     http.StatusBadRequest,
+		`{"name":" x "}`:     http.StatusBadRequest,
+		`{"name":" Alice "}`: http.StatusOK,
+	} {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodPatch, "/profile", strings.NewReader(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Set("user", user)
+		UpdateProfile(c)
+		if w.Code != want {
+			t.Errorf("PATCH /profile %s: status %d, want %d", body, w.Code, want)
+		}
+	}
+
+	initializers.DB.First(&user, user.ID)
+	if user.Name != "Alice" {
+		t.Errorf("name is %q, want Alice", user.Name)
+	}
+}
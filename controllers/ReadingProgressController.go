@@ -0,0 +1,156 @@
+package controllers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/Algoritma-dan-Pemrograman-ITS/Framework-Programming-GIN-GORM/initializers"
+	"github.com/Algoritma-dan-Pemrograman-ITS/Framework-Programming-GIN-GORM/models"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+var readingStatuses = map[string]bool{
+	models.StatusWantToRead: true,
+	models.StatusReading:    true,
+	models.StatusCompleted:  true,
+	models.StatusDropped:    true,
+}
+
+// UpdateReadingProgress creates or updates the current user's progress in a novel.
+// Use Raw JSON PUT with optional params: "status", "last_chapter", "percentage".
+// When only "last_chapter" is sent the percentage is computed from the published chapters.
+func UpdateReadingProgress(c *gin.Context) {
+	var body struct {
+		Status      string   `json:"status"`
+		LastChapter *int     `json:"last_chapter" binding:"omitempty,min=0"`
+		Percentage  *float64 `json:"percentage" binding:"omitempty,min=0,max=100"`
+	}
+
+	if err := c.BindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if body.Status != "" && !readingStatuses[body.Status] {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid status, allowed: want_to_read, reading, completed, dropped"})
+		return
+	}
+
+	user, ok := getUserFromContext(c)
+	if !ok {
+		return
+	}
+
+	var novel models.Novel
+	if result := initializers.DB.First(&novel, c.Param("novel_id")); result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Novel not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	var progress models.ReadingProgress
+	result := initializers.DB.Where("user_id = ? AND novel_id = ?", user.ID, novel.ID).First(&progress)
+	if result.Error != nil && result.Error != gorm.ErrRecordNotFound {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	if result.Error == gorm.ErrRecordNotFound {
+		progress = models.ReadingProgress{UserID: user.ID, NovelID: novel.ID, Status: models.StatusReading}
+	}
+
+	if body.Status != "" {
+		progress.Status = body.Status
+	}
+
+	read := false
+	if body.LastChapter != nil {
+		progress.LastChapter = body.LastChapter
+		read = true
+		if body.Percentage == nil {
+			progress.Percentage = chapterPercentage(novel.ID, *body.LastChapter)
+		}
+	}
+	if body.Percentage != nil {
+		progress.Percentage = *body.Percentage
+		read = true
+	}
+	if progress.Status == models.StatusCompleted {
+		progress.Percentage = 100
+	}
+	if read {
+		now := time.Now()
+		progress.LastReadAt = &now
+	}
+
+	if result := initializers.DB.Save(&progress); result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not save reading progress"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"progress": progress})
+}
+
+// GetReadingProgress lists the current user's progress, most recently read first. Optional ?status= filter.
+func GetReadingProgress(c *gin.Context) {
+	user, ok := getUserFromContext(c)
+	if !ok {
+		return
+	}
+
+	// Novels in the trash drop off the shelf until they are restored
+	query := initializers.DB.Where("user_id = ? AND novel_id IN (?)", user.ID, initializers.DB.Model(&models.Novel{}).Select("id"))
+	if status := c.Query("status"); status != "" {
+		if !readingStatuses[status] {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid status, allowed: want_to_read, reading, completed, dropped"})
+			return
+		}
+		query = query.Where("status = ?", status)
+	}
+
+	var progress []models.ReadingProgress
+	// Rows never read (want_to_read) have no last_read_at, keep them after the others
+	err := query.Preload("Novel").
+		Order("CASE WHEN last_read_at IS NULL THEN 1 ELSE 0 END, last_read_at DESC, updated_at DESC").
+		Find(&progress).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not fetch reading progress"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"progress": progress})
+}
+
+// RemoveReadingProgress forgets the current user's progress in a novel
+func RemoveReadingProgress(c *gin.Context) {
+	user, ok := getUserFromContext(c)
+	if !ok {
+		return
+	}
+
+	result := initializers.DB.Where("user_id = ? AND novel_id = ?", user.ID, c.Param("novel_id")).Delete(&models.ReadingProgress{})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not remove reading progress"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Reading progress not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Reading progress removed successfully"})
+}
+
+// chapterPercentage is the share of published chapters up to and including the given chapter number
+func chapterPercentage(novelID uint, chapterNumber int) float64 {
+	var total, read int64
+	publishedChapters(initializers.DB.Model(&models.Chapter{})).Where("novel_id = ?", novelID).Count(&total)
+	if total == 0 {
+		return 0
+	}
+	publishedChapters(initializers.DB.Model(&models.Chapter{})).Where("novel_id = ? AND number <= ?", novelID, chapterNumber).Count(&read)
+	return float64(int(float64(read)/float64(total)*10000)) / 100
+}
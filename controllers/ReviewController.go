@@ -5,13 +5,15 @@ import (
 
 	"github.com/Algoritma-dan-Pemrograman-ITS/Framework-Programming-GIN-GORM/initializers"
 	"github.com/Algoritma-dan-Pemrograman-ITS/Framework-Programming-GIN-GORM/models"
+	"github.com/Algoritma-dan-Pemrograman-ITS/Framework-Programming-GIN-GORM/utils"
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 // To Create Review Handles
 func CreateReview(c *gin.Context) {
 	var body struct {
-		Rating  float64 `json:"rating" binding:"required"`
+		Rating  float64 `json:"rating" binding:"required,min=1,max=5"`
 		Comment string  `json:"comment" binding:"required"`
 	}
 
@@ -21,7 +23,7 @@ func CreateReview(c *gin.Context) {
 	}
 
 	// Get Novel ID from URL parameter
-	novelID := c.Param("novelID")
+	novelID := c.Param("id")
 	var novel models.Novel
 	if result := initializers.DB.First(&novel, novelID); result.Error != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Novel not found"})
@@ -42,8 +44,15 @@ func CreateReview(c *gin.Context) {
 		NovelID: novel.ID,
 		UserID:  user.ID,
 	}
-	if result := initializers.DB.Create(&review); result.Error != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": result.Error.Error()})
+	// Save the review and refresh the novel rating together
+	err := initializers.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&review).Error; err != nil {
+			return err
+		}
+		return models.RecalculateNovelRating(tx, novel.ID)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
@@ -55,6 +64,13 @@ func GetReviewsByNovel(c *gin.Context) {
 	novelID := c.Param("id")
 	var reviews []models.Review
 
+	// Reviews of a novel in the trash are hidden with it
+	var novel models.Novel
+	if result := initializers.DB.First(&novel, novelID); result.Error != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Novel not found"})
+		return
+	}
+
 	if result := initializers.DB.Where("novel_id = ?", novelID).Preload("User").Find(&reviews); result.Error != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": result.Error.Error()})
 		return
@@ -79,7 +95,7 @@ func GetReviewByID(c *gin.Context) {
 func UpdateReview(c *gin.Context) {
 	reviewID := c.Param("reviewID")
 	var body struct {
-		Rating  float64 `json:"rating"`
+		Rating  float64 `json:"rating" binding:"omitempty,min=1,max=5"`
 		Comment string  `json:"comment"`
 	}
 
@@ -102,8 +118,8 @@ func UpdateReview(c *gin.Context) {
 	}
 	currentUser := userCtx.(models.User)
 
-	// Only the author of the review or an admin can update the review
-	if review.UserID != currentUser.ID && currentUser.Role != "admin" {
+	// Only the author of the review or a moderator can update the review
+	if review.UserID != currentUser.ID && utils.CheckPermission(c, currentUser, models.PermReviewModerate) != "" {
 		c.JSON(http.StatusForbidden, gin.H{"error": "You are not allowed to update this review"})
 		return
 	}
@@ -112,8 +128,14 @@ func UpdateReview(c *gin.Context) {
 	review.Rating = body.Rating
 	review.Comment = body.Comment
 
-	if result := initializers.DB.Model(&review).Updates(review); result.Error != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": result.Error.Error()})
+	err := initializers.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&review).Updates(review).Error; err != nil {
+			return err
+		}
+		return models.RecalculateNovelRating(tx, review.NovelID)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
@@ -139,14 +161,20 @@ func DeleteReview(c *gin.Context) {
 	}
 	currentUser := userCtx.(models.User)
 
-	// Only the author of the review or an admin can update the review
-	if review.UserID != currentUser.ID && currentUser.Role != "admin" {
-		c.JSON(http.StatusForbidden, gin.H{"error": "You are not allowed to update this review"})
+	// Only the author of the review or a moderator can remove the review
+	if review.UserID != currentUser.ID && utils.CheckPermission(c, currentUser, models.PermReviewModerate) != "" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You are not allowed to delete this review"})
 		return
 	}
 
-	if result := initializers.DB.Delete(&review); result.Error != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": result.Error.Error()})
+	err := initializers.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&review).Error; err != nil {
+			return err
+		}
+		return models.RecalculateNovelRating(tx, review.NovelID)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
@@ -0,0 +1,118 @@
+package controllers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/Algoritma-dan-Pemrograman-ITS/Framework-Programming-GIN-GORM/initializers"
+	"github.com/Algoritma-dan-Pemrograman-ITS/Framework-Programming-GIN-GORM/models"
+	"github.com/gin-gonic/gin"
+)
+
+// createReviewByOther stores a review written by someone else and returns it with a moderator
+func createReviewByOther(t *testing.T) (models.Review, models.User) {
+	t.Helper()
+	author := models.User{Name: "Author", Email: "author@example.com", Role: models.RoleUser}
+	moderator := models.User{Name: "Moderator", Email: "mod@example.com", Role: models.RoleModerator}
+	novel := models.Novel{Title: "Dune", Author: "Frank Herbert", Language: "english", YearPublished: 1965}
+	for _, record := range []interface{}{&author, &moderator, &novel} {
+		if err := initializers.DB.Create(record).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	review := models.Review{Rating: 4, Comment: "Good", UserID: author.ID, NovelID: novel.ID}
+	if err := initializers.DB.Create(&review).Error; err != nil {
+		t.Fatal(err)
+	}
+	return review, moderator
+}
+
+// moderateReview sends PUT /reviews/:reviewID as user, authenticated with key when it is not nil
+func moderateReview(review models.Review, user models.User, key *models.APIKey) int {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPut, "/reviews/"+strconv.Itoa(int(review.ID)), strings.NewReader(`{"rating":1,"comment":"Removed by a moderator"}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Params = gin.Params{{Key: "reviewID", Value: strconv.Itoa(int(review.ID))}}
+	c.Set("user", user)
+	if key != nil {
+		c.Set("api_key", *key)
+	}
+	UpdateReview(c)
+	return w.Code
+}
+
+func TestModerateReviewChecksAPIKeyScope(t *testing.T) {
+	setupTestDB(t)
+	review, moderator := createReviewByOther(t)
+
+	if code := moderateReview(review, moderator, &models.APIKey{Scopes: "write"}); code != http.StatusForbidden {
+		t.Errorf("write-only API key moderated a review: status %d, want 403", code)
+	}
+	if code := moderateReview(review, moderator, &models.APIKey{Scopes: "write review:moderate"}); code != http.StatusOK {
+		t.Errorf("API key with review:moderate: status %d, want 200", code)
+	}
+	if code := moderateReview(review, moderator, nil); code != http.StatusOK {
+		t.Errorf("moderator session: status %d, want 200", code)
+	}
+}
+
+func TestModerateReviewRequiresAdminTwoFactor(t *testing.T) {
+	setupTestDB(t)
+	t.Setenv("REQUIRE_ADMIN_2FA", "true")
+	review, moderator := createReviewByOther(t)
+	admin := models.User{Name: "Admin", Email: "admin@example.com", Role: models.RoleAdmin}
+	initializers.DB.Create(&admin)
+
+	if code := moderateReview(review, admin, nil); code != http.StatusForbidden {
+		t.Errorf("admin without 2FA: status %d, want 403", code)
+	}
+	admin.TOTPEnabled = true
+	if code := moderateReview(review, admin, nil); code != http.StatusOK {
+		t.Errorf("admin with 2FA: status %d, want 200", code)
+	}
+	if code := moderateReview(review, moderator, nil); code != http.StatusOK {
+		t.Errorf("moderator without 2FA: status %d, want 200", code)
+	}
+}
+
+func TestReviewsKeepNovelRatingInSync(t *testing.T) {
+	setupTestDB(t)
+	novel := models.Novel{Title: "Dune", Author: "Frank Herbert", Language: "english", YearPublished: 1965}
+	initializers.DB.Create(&novel)
+	novelParam := gin.Params{{Key: "id", Value: strconv.Itoa(int(novel.ID))}}
+
+	var reviewIDs []string
+	for i, rating := range []string{"5", "4"} {
+		reader := models.User{Name: "Reader", Email: "reader" + strconv.Itoa(i) + "@example.com"}
+		initializers.DB.Create(&reader)
+		w := callNovelHandler(t, reader, CreateReview, http.MethodPost, `{"rating":`+rating+`,"comment":"Read it"}`, novelParam)
+		if w.Code != http.StatusCreated {
+			t.Fatalf("create review: status %d: %s", w.Code, w.Body)
+		}
+		var review models.Review
+		initializers.DB.Last(&review)
+		reviewIDs = append(reviewIDs, strconv.Itoa(int(review.ID)))
+	}
+
+	// Weighted with the default prior: (5*3 + 9) / (5 + 2) = 3.43
+	initializers.DB.First(&novel, novel.ID)
+	if novel.ReviewCount != 2 || novel.AverageRating != 4.5 || novel.Rating != 3.43 || novel.RatingHistogram["5"] != 1 {
+		t.Errorf("after two reviews got count %d, average %v, rating %v, histogram %v", novel.ReviewCount, novel.AverageRating, novel.Rating, novel.RatingHistogram)
+	}
+
+	// The author removes the 5 star review
+	var first models.Review
+	initializers.DB.Preload("User").First(&first, reviewIDs[0])
+	if w := callNovelHandler(t, first.User, DeleteReview, http.MethodDelete, "", gin.Params{{Key: "reviewID", Value: reviewIDs[0]}}); w.Code != http.StatusOK {
+		t.Fatalf("delete review: status %d: %s", w.Code, w.Body)
+	}
+
+	initializers.DB.First(&novel, novel.ID)
+	if novel.ReviewCount != 1 || novel.AverageRating != 4 || novel.RatingHistogram["5"] != 0 || novel.RatingHistogram["4"] != 1 {
+		t.Errorf("after delete got count %d, average %v, histogram %v", novel.ReviewCount, novel.AverageRating, novel.RatingHistogram)
+	}
+}
@@ -0,0 +1,89 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/Algoritma-dan-Pemrograman-ITS/Framework-Programming-GIN-GORM/initializers"
+	"github.com/Algoritma-dan-Pemrograman-ITS/Framework-Programming-GIN-GORM/models"
+	"github.com/gin-gonic/gin"
+)
+
+// GetRoles lists every role with the permissions it grants
+func GetRoles(c *gin.Context) {
+	type roleResponse struct {
+		Name        string   `json:"name"`
+		Permissions []string `json:"permissions"`
+		UserCount   int64    `json:"user_count"`
+	}
+
+	roles := make([]roleResponse, 0, len(models.Roles))
+	for _, name := range models.Roles {
+		var count int64
+		if err := initializers.DB.Model(&models.User{}).Where("role = ?", name).Count(&count).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not count users"})
+			return
+		}
+		roles = append(roles, roleResponse{Name: name, Permissions: models.RolePermissions[name], UserCount: count})
+	}
+
+	c.JSON(http.StatusOK, gin.H{"roles": roles})
+}
+
+// AssignRole changes the role of a user, for both PUT /users/:id/role and PATCH /users/:id. Body: "role"
+func AssignRole(c *gin.Context) {
+	var body struct {
+		Role string `json:"role" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	currentUser, ok := getUserFromContext(c)
+	if !ok {
+		return
+	}
+
+	var user models.User
+	if result := initializers.DB.First(&user, c.Param("id")); result.Error != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	if status, errMsg := changeRole(currentUser, &user, body.Role); errMsg != "" {
+		c.JSON(status, gin.H{"error": errMsg})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"user": summarizeUser(user)})
+}
+
+// changeRole validates and saves a role change made by currentUser.
+// Nobody can change their own role, and the last admin cannot be demoted.
+func changeRole(currentUser *models.User, user *models.User, role string) (int, string) {
+	if !models.IsValidRole(role) {
+		return http.StatusBadRequest, "Unknown role, expected one of: admin, moderator, editor, user"
+	}
+
+	if currentUser.ID == user.ID {
+		return http.StatusForbidden, "You cannot change your own role"
+	}
+
+	if user.Role == role {
+		return 0, ""
+	}
+
+	if user.Role == models.RoleAdmin {
+		var admins int64
+		initializers.DB.Model(&models.User{}).Where("role = ?", models.RoleAdmin).Count(&admins)
+		if admins <= 1 {
+			return http.StatusConflict, "Cannot demote the last admin"
+		}
+	}
+
+	if err := initializers.DB.Model(user).Update("role", role).Error; err != nil {
+		return http.StatusInternalServerError, "Could not update role"
+	}
+
+	return 0, ""
+}
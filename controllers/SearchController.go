@@ -0,0 +1,152 @@
+package controllers
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Algoritma-dan-Pemrograman-ITS/Framework-Programming-GIN-GORM/initializers"
+	"github.com/Algoritma-dan-Pemrograman-ITS/Framework-Programming-GIN-GORM/models"
+	"github.com/Algoritma-dan-Pemrograman-ITS/Framework-Programming-GIN-GORM/search"
+	"github.com/Algoritma-dan-Pemrograman-ITS/Framework-Programming-GIN-GORM/utils"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Search results are always ordered by relevance
+var searchSortFields = map[string]string{"relevance": "relevance"}
+
+type searchResult struct {
+	Novel      models.Novel      `json:"novel"`
+	Score      float64           `json:"score"`
+	Highlights map[string]string `json:"highlights"`
+}
+
+// SearchNovels searches titles and authors, tolerating typos unless ?fuzzy=false.
+// Example: /search?q=tolkin+hobit&language=english&year=1937&page=1&page_size=10
+func SearchNovels(c *gin.Context) {
+	text := strings.TrimSpace(c.Query("q"))
+	if text == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "q is required"})
+		return
+	}
+
+	pagination, errMsg := utils.ParsePagination(c, searchSortFields, "relevance")
+	if errMsg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errMsg})
+		return
+	}
+
+	query := search.Query{
+		Text:     text,
+		Language: c.Query("language"),
+		Fuzzy:    c.Query("fuzzy") != "false",
+		Offset:   (pagination.Page - 1) * pagination.PageSize,
+		Limit:    pagination.PageSize,
+	}
+	if year := c.Query("year"); year != "" {
+		yearInt, err := strconv.Atoi(year)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "year must be a number"})
+			return
+		}
+		query.Year = yearInt
+	}
+
+	result, err := search.Default.Search(query)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ids := make([]uint, 0, len(result.Hits))
+	for _, hit := range result.Hits {
+		ids = append(ids, hit.ID)
+	}
+	var novels []models.Novel
+	if len(ids) > 0 {
+		if err := initializers.DB.Preload("Genres").Preload("Tags").Where("id IN ?", ids).Find(&novels).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	byID := make(map[uint]models.Novel, len(novels))
+	for _, novel := range novels {
+		byID[novel.ID] = novel
+	}
+
+	// Keep the relevance order of the index
+	results := make([]searchResult, 0, len(result.Hits))
+	for _, hit := range result.Hits {
+		novel, ok := byID[hit.ID]
+		if !ok {
+			continue
+		}
+		results = append(results, searchResult{Novel: novel, Score: hit.Score, Highlights: hit.Highlights})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"results":    results,
+		"facets":     result.Facets,
+		"pagination": pagination.Info(c, int64(result.Total)),
+	})
+}
+
+func novelDocument(novel models.Novel) search.Document {
+	return search.Document{
+		ID:       novel.ID,
+		Title:    novel.Title,
+		Author:   novel.Author,
+		Language: novel.Language,
+		Year:     novel.YearPublished,
+	}
+}
+
+// indexNovels keeps the search index in sync after a write. The database is already committed,
+// so a failure is only logged; the next SyncSearchIndex rebuilds the index.
+func indexNovels(novels ...models.Novel) {
+	docs := make([]search.Document, 0, len(novels))
+	for _, novel := range novels {
+		docs = append(docs, novelDocument(novel))
+	}
+	if err := search.Default.Index(docs...); err != nil {
+		log.Printf("search: could not index novels: %v", err)
+	}
+}
+
+func unindexNovels(ids ...uint) {
+	if err := search.Default.Delete(ids...); err != nil {
+		log.Printf("search: could not remove novels: %v", err)
+	}
+}
+
+// SyncSearchIndex rebuilds the search index from the database when the number of documents
+// doesn't match the number of novels (first start, memory backend, or writes made elsewhere)
+func SyncSearchIndex() error {
+	var count int64
+	if err := initializers.DB.Model(&models.Novel{}).Count(&count).Error; err != nil {
+		return err
+	}
+	if int(count) == search.Default.Count() {
+		return nil
+	}
+
+	docs := make([]search.Document, 0, count)
+	var batch []models.Novel
+	err := initializers.DB.Model(&models.Novel{}).FindInBatches(&batch, 500, func(tx *gorm.DB, _ int) error {
+		for _, novel := range batch {
+			docs = append(docs, novelDocument(novel))
+		}
+		return nil
+	}).Error
+	if err != nil {
+		return err
+	}
+
+	if err := search.Default.Reset(docs); err != nil {
+		return err
+	}
+	log.Printf("search: rebuilt index with %d novels", len(docs))
+	return nil
+}
@@ -0,0 +1,80 @@
+package controllers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/Algoritma-dan-Pemrograman-ITS/Framework-Programming-GIN-GORM/initializers"
+	"github.com/Algoritma-dan-Pemrograman-ITS/Framework-Programming-GIN-GORM/models"
+	"github.com/Algoritma-dan-Pemrograman-ITS/Framework-Programming-GIN-GORM/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type sessionResponse struct {
+	models.Session
+	Current bool `json:"current"`
+}
+
+// currentFamilyID returns the session ("fid" claim) of the access token used for this request
+func currentFamilyID(c *gin.Context) string {
+	tokenString, _ := accessTokenFromRequest(c)
+	if tokenString == "" {
+		return ""
+	}
+	claims, err := utils.ParseAccessToken(tokenString)
+	if err != nil {
+		return ""
+	}
+	return claims.FamilyID
+}
+
+// GetSessions lists the devices the logged in user is signed in on, most recently used first
+func GetSessions(c *gin.Context) {
+	user, ok := getUserFromContext(c)
+	if !ok {
+		return
+	}
+
+	// A session unused for longer than the refresh token lifetime cannot be resumed anymore
+	activeSince := time.Now().Add(-utils.RefreshTokenTTL())
+
+	var sessions []models.Session
+	if err := initializers.DB.Where("user_id = ? AND revoked_at IS NULL AND last_seen_at > ?", user.ID, activeSince).
+		Order("last_seen_at desc").Find(&sessions).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not load sessions"})
+		return
+	}
+
+	current := currentFamilyID(c)
+	response := make([]sessionResponse, 0, len(sessions))
+	for _, session := range sessions {
+		response = append(response, sessionResponse{Session: session, Current: session.FamilyID == current})
+	}
+
+	c.JSON(http.StatusOK, gin.H{"sessions": response})
+}
+
+// RevokeSession logs one device out; its access and refresh tokens stop working immediately
+func RevokeSession(c *gin.Context) {
+	user, ok := getUserFromContext(c)
+	if !ok {
+		return
+	}
+
+	var session models.Session
+	if result := initializers.DB.Where("id = ? AND user_id = ? AND revoked_at IS NULL", c.Param("id"), user.ID).First(&session); result.Error != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+		return
+	}
+
+	if err := revokeFamily(session.FamilyID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not revoke session"})
+		return
+	}
+
+	if session.FamilyID == currentFamilyID(c) {
+		clearAuthCookies(c)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Session revoked"})
+}
@@ -0,0 +1,111 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/Algoritma-dan-Pemrograman-ITS/Framework-Programming-GIN-GORM/initializers"
+	"github.com/Algoritma-dan-Pemrograman-ITS/Framework-Programming-GIN-GORM/middleware"
+	"github.com/Algoritma-dan-Pemrograman-ITS/Framework-Programming-GIN-GORM/models"
+	"github.com/gin-gonic/gin"
+)
+
+// sessionRouter serves the session routes behind the real AuthMiddleware
+func sessionRouter() *gin.Engine {
+	router := gin.New()
+	router.Use(middleware.AuthMiddleware)
+	router.GET("/profile/sessions", GetSessions)
+	router.DELETE("/profile/sessions/:id", RevokeSession)
+	return router
+}
+
+// loginDevice starts another session for user and returns its access token
+func loginDevice(t *testing.T, user models.User) string {
+	t.Helper()
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodPost, "/login", nil)
+	tokens, err := startSession(c, user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tokens.AccessToken
+}
+
+func serveWithToken(router *gin.Engine, method, path, token string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(method, path, nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestRevokeSessionRejectsItsAccessToken(t *testing.T) {
+	setupTestDB(t)
+	t.Setenv("JWT_SECRET", "test-secret")
+	router := sessionRouter()
+
+	user, _ := loginCookies(t)
+	laptop := loginDevice(t, user)
+	phone := loginDevice(t, user)
+
+	w := serveWithToken(router, http.MethodGet, "/profile/sessions", laptop)
+	var response struct {
+		Sessions []sessionResponse `json:"sessions"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	if len(response.Sessions) != 3 {
+		t.Fatalf("got %d sessions, want 3", len(response.Sessions))
+	}
+
+	// Find the phone's session: the current one when asking with the phone's token
+	w = serveWithToken(router, http.MethodGet, "/profile/sessions", phone)
+	json.Unmarshal(w.Body.Bytes(), &response)
+	var phoneSession uint
+	for _, session := range response.Sessions {
+		if session.Current {
+			phoneSession = session.ID
+		}
+	}
+	if phoneSession == 0 {
+		t.Fatal("no session marked current")
+	}
+
+	if w := serveWithToken(router, http.MethodDelete, "/profile/sessions/"+strconv.Itoa(int(phoneSession)), laptop); w.Code != http.StatusOK {
+		t.Fatalf("revoke: status %d: %s", w.Code, w.Body)
+	}
+
+	if w := serveWithToken(router, http.MethodGet, "/profile/sessions", phone); w.Code != http.StatusUnauthorized {
+		t.Errorf("revoked device: status %d, want 401", w.Code)
+	}
+	if w := serveWithToken(router, http.MethodGet, "/profile/sessions", laptop); w.Code != http.StatusOK {
+		t.Errorf("other device: status %d, want 200", w.Code)
+	}
+	if activeSessions(t, user.ID) != 2 {
+		t.Errorf("got %d active sessions, want 2", activeSessions(t, user.ID))
+	}
+}
+
+func TestRevokeSessionOfAnotherUser(t *testing.T) {
+	setupTestDB(t)
+	t.Setenv("JWT_SECRET", "test-secret")
+	router := sessionRouter()
+
+	alice, _ := loginCookies(t)
+	bob := models.User{Name: "Bob", Email: "bob@example.com"}
+	if err := initializers.DB.Create(&bob).Error; err != nil {
+		t.Fatal(err)
+	}
+	bobToken := loginDevice(t, bob)
+
+	var aliceSession models.Session
+	initializers.DB.Where("user_id = ?", alice.ID).First(&aliceSession)
+	if w := serveWithToken(router, http.MethodDelete, "/profile/sessions/"+strconv.Itoa(int(aliceSession.ID)), bobToken); w.Code != http.StatusNotFound {
+		t.Errorf("revoking someone else's session: status %d, want 404", w.Code)
+	}
+	if activeSessions(t, alice.ID) != 1 {
+		t.Error("another user's session was revoked")
+	}
+}
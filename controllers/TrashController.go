@@ -0,0 +1,129 @@
+package controllers
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/Algoritma-dan-Pemrograman-ITS/Framework-Programming-GIN-GORM/initializers"
+	"github.com/Algoritma-dan-Pemrograman-ITS/Framework-Programming-GIN-GORM/models"
+	"github.com/Algoritma-dan-Pemrograman-ITS/Framework-Programming-GIN-GORM/utils"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultTrashRetention     = 30 * 24 * time.Hour
+	defaultTrashPurgeInterval = time.Hour
+)
+
+// trashRetention is how long a removed novel stays restorable (TRASH_RETENTION)
+func trashRetention() time.Duration {
+	return utils.DurationFromEnv("TRASH_RETENTION", defaultTrashRetention)
+}
+
+var trashSortFields = map[string]string{
+	"deleted": "deleted_at",
+	"title":   "title",
+}
+
+type trashedNovel struct {
+	models.Novel
+	DeletedAt time.Time `json:"deleted_at"`
+	PurgeAt   time.Time `json:"purge_at"`
+}
+
+// GetTrash lists removed novels, most recently removed first, with the time each one is purged.
+// Example: localhost:8001/admin/trash?sort=title&page=1
+func GetTrash(c *gin.Context) {
+	pagination, errMsg := utils.ParsePagination(c, trashSortFields, "-deleted")
+	if errMsg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errMsg})
+		return
+	}
+
+	query := initializers.DB.Unscoped().Model(&models.Novel{}).Where("novels.deleted_at IS NOT NULL")
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	var novels []models.Novel
+	if err := pagination.Apply(query, "novels").Preload("Genres").Preload("Tags").Find(&novels).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	retention := trashRetention()
+	trash := make([]trashedNovel, 0, len(novels))
+	for _, novel := range novels {
+		deletedAt := novel.DeletedAt.Time
+		trash = append(trash, trashedNovel{Novel: novel, DeletedAt: deletedAt, PurgeAt: deletedAt.Add(retention)})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"novels":     trash,
+		"pagination": pagination.Info(c, total),
+	})
+}
+
+// RestoreNovel takes a novel out of the trash, with its chapters, reviews and bookmarks
+func RestoreNovel(c *gin.Context) {
+	var novel models.Novel
+	if result := initializers.DB.Unscoped().Where("id = ? AND deleted_at IS NOT NULL", c.Param("id")).First(&novel); result.Error != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Novel not found in trash"})
+		return
+	}
+
+	if err := initializers.DB.Unscoped().Model(&novel).Update("deleted_at", nil).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	initializers.DB.Preload("Genres").Preload("Tags").First(&novel, novel.ID)
+	indexNovels(novel)
+	c.JSON(http.StatusOK, gin.H{"novel": novel})
+}
+
+// PurgeTrash permanently deletes the novels removed before cutoff, like RemoveNovel used to:
+// chapters explicitly, reviews, bookmarks and progress through the foreign key cascade.
+func PurgeTrash(cutoff time.Time) (int, error) {
+	var ids []uint
+	if err := initializers.DB.Unscoped().Model(&models.Novel{}).
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", cutoff).
+		Pluck("id", &ids).Error; err != nil {
+		return 0, err
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	err := initializers.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("novel_id IN ?", ids).Delete(&models.Chapter{}).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Where("id IN ?", ids).Delete(&models.Novel{}).Error
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(ids), nil
+}
+
+// StartTrashPurger purges expired novels now and then every TRASH_PURGE_INTERVAL (default 1h)
+func StartTrashPurger() {
+	interval := utils.DurationFromEnv("TRASH_PURGE_INTERVAL", defaultTrashPurgeInterval)
+	go func() {
+		for {
+			purged, err := PurgeTrash(time.Now().Add(-trashRetention()))
+			if err != nil {
+				log.Printf("trash: purge failed: %v", err)
+			} else if purged > 0 {
+				log.Printf("trash: purged %d novels", purged)
+			}
+			time.Sleep(interval)
+		}
+	}()
+}
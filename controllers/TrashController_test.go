@@ -0,0 +1,80 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/Algoritma-dan-Pemrograman-ITS/Framework-Programming-GIN-GORM/initializers"
+	"github.com/Algoritma-dan-Pemrograman-ITS/Framework-Programming-GIN-GORM/models"
+	"github.com/gin-gonic/gin"
+)
+
+// trashedNovelWithChapter creates a fantasy novel with a published chapter and moves it to the trash
+func trashedNovelWithChapter(t *testing.T) models.Novel {
+	t.Helper()
+	genre := models.Genre{Name: "Fantasy", Slug: "fantasy"}
+	if err := initializers.DB.Create(&genre).Error; err != nil {
+		t.Fatal(err)
+	}
+	kept := models.Novel{Title: "The Hobbit", Author: "J.R.R. Tolkien", Genres: []models.Genre{genre}}
+	trashed := models.Novel{Title: "Eragon", Author: "Christopher Paolini", Genres: []models.Genre{genre}}
+	for _, novel := range []*models.Novel{&kept, &trashed} {
+		if err := initializers.DB.Create(novel).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	published := time.Now().Add(-time.Hour)
+	chapter := models.Chapter{NovelID: trashed.ID, Number: 1, Title: "Discovery", Body: "A stone", PublishedAt: &published}
+	if err := initializers.DB.Create(&chapter).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	if err := initializers.DB.Delete(&trashed).Error; err != nil {
+		t.Fatal(err)
+	}
+	return trashed
+}
+
+func TestGetGenresSkipsTrashedNovels(t *testing.T) {
+	setupTestDB(t)
+	trashedNovelWithChapter(t)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/genres", nil)
+	GetGenres(c)
+
+	var response struct {
+		Genres []struct {
+			Slug       string `json:"slug"`
+			NovelCount int64  `json:"novel_count"`
+		} `json:"genres"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatal(err)
+	}
+	if len(response.Genres) != 1 || response.Genres[0].NovelCount != 1 {
+		t.Errorf("got genres %+v, want fantasy with 1 novel", response.Genres)
+	}
+}
+
+func TestGetChapterOfTrashedNovel(t *testing.T) {
+	setupTestDB(t)
+	trashed := trashedNovelWithChapter(t)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	id := strconv.Itoa(int(trashed.ID))
+	c.Request = httptest.NewRequest(http.MethodGet, "/novels/"+id+"/chapters/1", nil)
+	c.Params = gin.Params{{Key: "id", Value: id}, {Key: "number", Value: "1"}}
+	GetChapter(c)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("chapter of a trashed novel: status %d, want 404", w.Code)
+	}
+}
@@ -0,0 +1,207 @@
+package controllers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/Algoritma-dan-Pemrograman-ITS/Framework-Programming-GIN-GORM/initializers"
+	"github.com/Algoritma-dan-Pemrograman-ITS/Framework-Programming-GIN-GORM/models"
+	"github.com/Algoritma-dan-Pemrograman-ITS/Framework-Programming-GIN-GORM/utils"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Public sort names accepted by GET /users
+var userSortFields = map[string]string{
+	"id":    "id",
+	"name":  "name",
+	"email": "email",
+	"role":  "role",
+}
+
+// userSummary is the admin view of an account (no password, TOTP secret or relations)
+type userSummary struct {
+	ID               uint       `json:"id"`
+	Name             string     `json:"name"`
+	Email            string     `json:"email"`
+	Role             string     `json:"role"`
+	Permissions      []string   `json:"permissions"`
+	EmailVerified    bool       `json:"email_verified"`
+	TwoFactorEnabled bool       `json:"two_factor_enabled"`
+	SuspendedAt      *time.Time `json:"suspended_at"`
+	SuspensionReason string     `json:"suspension_reason,omitempty"`
+}
+
+func summarizeUser(user models.User) userSummary {
+	return userSummary{
+		ID:               user.ID,
+		Name:             user.Name,
+		Email:            user.Email,
+		Role:             user.Role,
+		Permissions:      user.Permissions(),
+		EmailVerified:    user.EmailVerified,
+		TwoFactorEnabled: user.TOTPEnabled,
+		SuspendedAt:      user.SuspendedAt,
+		SuspensionReason: user.SuspensionReason,
+	}
+}
+
+// GetUsers lists accounts. Filters: ?q= (name or email contains), ?role=, ?suspended=true|false
+func GetUsers(c *gin.Context) {
+	pagination, errMsg := utils.ParsePagination(c, userSortFields, "id")
+	if errMsg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errMsg})
+		return
+	}
+
+	query := initializers.DB.Model(&models.User{})
+
+	if q := c.Query("q"); q != "" {
+		query = query.Where(utils.ContainsClause("name")+" OR "+utils.ContainsClause("email"),
+			utils.ContainsPattern(q), utils.ContainsPattern(q))
+	}
+	if role := c.Query("role"); role != "" {
+		query = query.Where("role = ?", role)
+	}
+	switch c.Query("suspended") {
+	case "true":
+		query = query.Where("suspended_at IS NOT NULL")
+	case "false":
+		query = query.Where("suspended_at IS NULL")
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	var users []models.User
+	if err := pagination.Apply(query, "users").Find(&users).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	summaries := make([]userSummary, 0, len(users))
+	for _, user := range users {
+		summaries = append(summaries, summarizeUser(user))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"users":      summaries,
+		"pagination": pagination.Info(c, total),
+	})
+}
+
+// GetUser returns one account with the number of reviews and bookmarks it has
+func GetUser(c *gin.Context) {
+	var user models.User
+	if result := initializers.DB.First(&user, c.Param("id")); result.Error != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	var reviewCount, bookmarkCount int64
+	if err := initializers.DB.Model(&models.Review{}).Where("user_id = ?", user.ID).Count(&reviewCount).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not count reviews"})
+		return
+	}
+	if err := initializers.DB.Table("user_bookmarks").Where("user_id = ?", user.ID).Count(&bookmarkCount).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not count bookmarks"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"user":           summarizeUser(user),
+		"review_count":   reviewCount,
+		"bookmark_count": bookmarkCount,
+	})
+}
+
+// SuspendUser blocks an account and logs it out everywhere. Body (optional): "reason".
+// Moderators can only suspend regular users; suspending staff needs user:manage.
+func SuspendUser(c *gin.Context) {
+	var body struct {
+		Reason string `json:"reason" binding:"max=255"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil && c.Request.ContentLength > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, ok := suspensionTarget(c)
+	if !ok {
+		return
+	}
+
+	if user.IsSuspended() {
+		c.JSON(http.StatusConflict, gin.H{"error": "User is already suspended"})
+		return
+	}
+
+	now := time.Now()
+	err := initializers.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(user).Updates(map[string]interface{}{
+			"suspended_at":      now,
+			"suspension_reason": body.Reason,
+		}).Error; err != nil {
+			return err
+		}
+		return revokeAllFamilies(tx, user.ID)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not suspend user"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"user": summarizeUser(*user)})
+}
+
+// UnsuspendUser lifts a suspension; the user has to log in again
+func UnsuspendUser(c *gin.Context) {
+	user, ok := suspensionTarget(c)
+	if !ok {
+		return
+	}
+
+	if !user.IsSuspended() {
+		c.JSON(http.StatusConflict, gin.H{"error": "User is not suspended"})
+		return
+	}
+
+	if err := initializers.DB.Model(user).Updates(map[string]interface{}{
+		"suspended_at":      nil,
+		"suspension_reason": "",
+	}).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not unsuspend user"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"user": summarizeUser(*user)})
+}
+
+// suspensionTarget loads the user from the :id param and checks the current user may (un)suspend it
+func suspensionTarget(c *gin.Context) (*models.User, bool) {
+	currentUser, ok := getUserFromContext(c)
+	if !ok {
+		return nil, false
+	}
+
+	var user models.User
+	if result := initializers.DB.First(&user, c.Param("id")); result.Error != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return nil, false
+	}
+
+	if user.ID == currentUser.ID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You cannot suspend your own account"})
+		return nil, false
+	}
+
+	if user.Role != models.RoleUser && utils.CheckPermission(c, *currentUser, models.PermUserManage) != "" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins can suspend staff accounts"})
+		return nil, false
+	}
+
+	return &user, true
+}
@@ -0,0 +1,49 @@
+package controllers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/Algoritma-dan-Pemrograman-ITS/Framework-Programming-GIN-GORM/initializers"
+	"github.com/Algoritma-dan-Pemrograman-ITS/Framework-Programming-GIN-GORM/models"
+	"github.com/gin-gonic/gin"
+)
+
+// callSuspension runs handler (SuspendUser or UnsuspendUser) on target as admin
+func callSuspension(handler gin.HandlerFunc, admin, target models.User) int {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/", nil)
+	c.Params = gin.Params{{Key: "id", Value: strconv.Itoa(int(target.ID))}}
+	c.Set("user", admin)
+	handler(c)
+	return w.Code
+}
+
+func TestSuspendAndUnsuspendOnlyChangeState(t *testing.T) {
+	setupTestDB(t)
+	admin := models.User{Name: "Admin", Email: "admin@example.com", Role: models.RoleAdmin}
+	user := models.User{Name: "Bob", Email: "bob@example.com", Role: models.RoleUser}
+	initializers.DB.Create(&admin)
+	initializers.DB.Create(&user)
+
+	if code := callSuspension(UnsuspendUser, admin, user); code != http.StatusConflict {
+		t.Errorf("unsuspend an active user: status %d, want 409", code)
+	}
+	if code := callSuspension(SuspendUser, admin, user); code != http.StatusOK {
+		t.Fatalf("suspend: status %d, want 200", code)
+	}
+	if code := callSuspension(SuspendUser, admin, user); code != http.StatusConflict {
+		t.Errorf("suspend twice: status %d, want 409", code)
+	}
+	if code := callSuspension(UnsuspendUser, admin, user); code != http.StatusOK {
+		t.Errorf("unsuspend: status %d, want 200", code)
+	}
+
+	initializers.DB.First(&user, user.ID)
+	if user.IsSuspended() {
+		t.Error("user still suspended")
+	}
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/Algoritma-dan-Pemrograman-ITS/Framework-Programming-GIN-GORM/controllers"
+	"github.com/Algoritma-dan-Pemrograman-ITS/Framework-Programming-GIN-GORM/initializers"
+)
+
+func init() {
+	initializers.LoadEnvVariables()
+	initializers.ConnectToDB()
+}
+
+const usage = `Usage:
+  go run export/Export.go novels  [flags]  novels with ratings, bookmark counts, genres and tags
+  go run export/Export.go reviews [flags]  reviews of the matching novels
+
+Flags:
+  -format csv|json|ndjson  output format (default csv)
+  -o FILE                  write to FILE instead of stdout
+  -title, -author, -language, -year_published, -genre, -genre_mode, -exclude_genre,
+  -tag, -tag_mode, -exclude_tag  the same filters as GET /novels
+
+Example:
+  go run export/Export.go novels -format ndjson -language english -genre fantasy -o fantasy.ndjson`
+
+// Filters accepted by GET /novels, forwarded as they are
+var filterNames = []string{
+	"title", "author", "language", "year_published",
+	"genre", "genre_mode", "exclude_genre", "tag", "tag_mode", "exclude_tag",
+}
+
+func main() {
+	if len(os.Args) < 2 || !slices.Contains(controllers.ExportTypes, os.Args[1]) {
+		fmt.Println(usage)
+		os.Exit(1)
+	}
+	kind := os.Args[1]
+
+	flags := flag.NewFlagSet(kind, flag.ExitOnError)
+	flags.Usage = func() { fmt.Println(usage) }
+	format := flags.String("format", "csv", "")
+	output := flags.String("o", "", "")
+	filterValues := make(map[string]*string, len(filterNames))
+	for _, name := range filterNames {
+		filterValues[name] = flags.String(name, "", "")
+	}
+	flags.Parse(os.Args[2:])
+
+	if !slices.Contains(controllers.ExportFormats, *format) {
+		log.Fatalf("Invalid format %q, allowed: %s", *format, strings.Join(controllers.ExportFormats, ", "))
+	}
+
+	filters := url.Values{}
+	for name, value := range filterValues {
+		if *value != "" {
+			filters.Set(name, *value)
+		}
+	}
+
+	out := os.Stdout
+	if *output != "" {
+		file, err := os.Create(*output)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer file.Close()
+		out = file
+	}
+
+	if err := controllers.WriteExport(out, kind, *format, filters); err != nil {
+		log.Fatal(err)
+	}
+}
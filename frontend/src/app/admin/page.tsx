@@ -21,7 +21,7 @@ export default function AdminDashboard() {
 
     useEffect(() => {
         if (!authLoading) {
-            if (!user || user.role !== 'admin') {
+            if (!user || !user.permissions?.includes('novel:write')) {
                 router.push('/');
             } else {
                 fetchNovels();
@@ -62,7 +62,7 @@ export default function AdminDashboard() {
         setIsModalOpen(true);
     };
 
-    if (authLoading || (user && user.role !== 'admin')) {
+    if (authLoading || (user && !user.permissions?.includes('novel:write'))) {
         return (
             <div className="min-h-screen flex items-center justify-center bg-gray-50">
                 <Loader2 className="animate-spin text-blue-600" size={32} />
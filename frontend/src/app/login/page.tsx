@@ -132,7 +132,7 @@ export default function LoginPage() {
           </div>
 
           <div className="flex justify-end">
-            <a href="#" className="text-xs font-medium text-blue-600 hover:text-blue-700 hover:underline">Forgot Password?</a>
+            <a href="/reset-password" className="text-xs font-medium text-blue-600 hover:text-blue-700 hover:underline">Forgot Password?</a>
           </div>
 
           {/* Submit Button */}
@@ -23,7 +23,7 @@ export default function Home() {
     async function fetchData() {
       try {
         setLoading(true);
-        const res = await fetch("http://localhost:8001/novels");
+        const res = await fetch("http://localhost:8001/novels?page_size=100");
 
         if (!res.ok) {
           throw new Error("Backend offline");
@@ -118,7 +118,7 @@ export default function Home() {
                   <span className="text-sm font-semibold text-slate-700">
                     Halo, {user.name} 👋
                   </span>
-                  {user.role === 'admin' && (
+                  {user.permissions?.includes('novel:write') && (
                     <Link href="/admin" className="text-sm text-blue-600 hover:text-blue-800 font-bold">
                       Admin Dashboard
                     </Link>
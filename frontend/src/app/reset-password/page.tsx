@@ -0,0 +1,172 @@
+"use client";
+
+import React, { Suspense, useState } from 'react';
+import { motion } from 'framer-motion';
+import { Mail, Lock, ArrowRight, Loader2, CheckCircle, AlertCircle } from 'lucide-react';
+import { useRouter, useSearchParams } from 'next/navigation';
+
+// Without ?token= the page asks for the email to send the link to (POST /password/forgot),
+// with the token from the email it sets the new password (POST /password/reset)
+function ResetPasswordForm() {
+  const token = useSearchParams().get('token') || '';
+  const [loading, setLoading] = useState(false);
+  const [email, setEmail] = useState('');
+  const [password, setPassword] = useState('');
+  const [error, setError] = useState('');
+  const [message, setMessage] = useState('');
+  const router = useRouter();
+
+  const handleSubmit = async (e: React.FormEvent) => {
+    e.preventDefault();
+    setError('');
+    setLoading(true);
+
+    try {
+      const response = await fetch(
+        token ? 'http://localhost:8001/password/reset' : 'http://localhost:8001/password/forgot',
+        {
+          method: 'POST',
+          headers: {
+            'Content-Type': 'application/json',
+          },
+          body: JSON.stringify(token ? { token: token, password: password } : { email: email }),
+        },
+      );
+
+      const data = await response.json();
+
+      if (!response.ok) {
+        setError(data.error || 'Request failed. Please try again.');
+        return;
+      }
+
+      setMessage(data.message);
+      setEmail('');
+      setPassword('');
+
+      if (token) {
+        // Every session was logged out, so login again with the new password
+        setTimeout(() => {
+          router.push('/login');
+        }, 2000);
+      }
+    } catch (err) {
+      setError('Failed to connect to server. Please check your connection.');
+      console.error('Reset password error:', err);
+    } finally {
+      setLoading(false);
+    }
+  };
+
+  return (
+    <div className="min-h-screen flex items-center justify-center bg-[#FAFAFA] px-4 font-sans text-slate-800">
+      <motion.div
+        initial={{ opacity: 0, y: 20 }}
+        animate={{ opacity: 1, y: 0 }}
+        transition={{ duration: 0.5 }}
+        className="max-w-md w-full bg-white rounded-2xl p-8 border border-gray-100 shadow-[0_8px_30px_rgb(0,0,0,0.04)]"
+      >
+        <div className="text-center mb-8">
+          <h1 className="text-2xl font-bold text-slate-900">{token ? 'Choose a new password' : 'Forgot your password?'}</h1>
+          <p className="text-slate-500 text-sm mt-2">
+            {token ? 'All your devices will be logged out.' : 'We will email you a link to reset it.'}
+          </p>
+        </div>
+
+        {/* Error Message */}
+        {error && (
+          <motion.div
+            initial={{ opacity: 0, y: -10 }}
+            animate={{ opacity: 1, y: 0 }}
+            className="mb-4 p-4 bg-red-50 border border-red-200 rounded-xl flex gap-3 items-start"
+          >
+            <AlertCircle size={20} className="text-red-500 mt-0.5 shrink-0" />
+            <p className="text-sm text-red-700">{error}</p>
+          </motion.div>
+        )}
+
+        {/* Success Message */}
+        {message && (
+          <motion.div
+            initial={{ opacity: 0, y: -10 }}
+            animate={{ opacity: 1, y: 0 }}
+            className="mb-4 p-4 bg-green-50 border border-green-200 rounded-xl flex gap-3 items-start"
+          >
+            <CheckCircle size={20} className="text-green-500 mt-0.5 shrink-0" />
+            <p className="text-sm text-green-700">{message}</p>
+          </motion.div>
+        )}
+
+        <form onSubmit={handleSubmit} className="space-y-5">
+          {token ? (
+            /* New Password Input */
+            <div className="space-y-1.5">
+              <label className="text-xs font-semibold text-slate-600 uppercase tracking-wide">New Password</label>
+              <div className="relative group">
+                <Lock className="absolute left-3 top-1/2 -translate-y-1/2 text-gray-400 group-focus-within:text-blue-600 transition-colors h-5 w-5" />
+                <input
+                  type="password"
+                  required
+                  minLength={8}
+                  value={password}
+                  onChange={(e) => setPassword(e.target.value)}
+                  disabled={loading || !!message}
+                  className="w-full pl-10 pr-4 py-3 bg-gray-50 border border-gray-200 rounded-xl text-slate-800 text-sm focus:bg-white focus:border-blue-500 focus:ring-4 focus:ring-blue-500/10 transition-all outline-none placeholder:text-gray-400 disabled:opacity-50"
+                  placeholder="At least 8 characters"
+                />
+              </div>
+            </div>
+          ) : (
+            /* Email Input */
+            <div className="space-y-1.5">
+              <label className="text-xs font-semibold text-slate-600 uppercase tracking-wide">Email</label>
+              <div className="relative group">
+                <Mail className="absolute left-3 top-1/2 -translate-y-1/2 text-gray-400 group-focus-within:text-blue-600 transition-colors h-5 w-5" />
+                <input
+                  type="email"
+                  required
+                  value={email}
+                  onChange={(e) => setEmail(e.target.value)}
+                  disabled={loading}
+                  className="w-full pl-10 pr-4 py-3 bg-gray-50 border border-gray-200 rounded-xl text-slate-800 text-sm focus:bg-white focus:border-blue-500 focus:ring-4 focus:ring-blue-500/10 transition-all outline-none placeholder:text-gray-400 disabled:opacity-50"
+                  placeholder="nama@email.com"
+                />
+              </div>
+            </div>
+          )}
+
+          {/* Submit Button */}
+          <button
+            type="submit"
+            disabled={loading || (!!token && !!message)}
+            className="w-full bg-blue-600 hover:bg-blue-700 text-white font-semibold py-3 rounded-xl transition-all active:scale-[0.98] flex items-center justify-center gap-2 shadow-lg shadow-blue-600/20 disabled:opacity-70 disabled:cursor-not-allowed"
+          >
+            {loading ? <Loader2 className="animate-spin h-5 w-5" /> : (
+              <>
+                {token ? 'Reset Password' : 'Send Reset Link'} <ArrowRight className="h-5 w-5" />
+              </>
+            )}
+          </button>
+        </form>
+
+        <div className="mt-8 text-center">
+          <p className="text-sm text-slate-500">
+            Remembered it?{' '}
+            <a href="/login" className="font-semibold text-blue-600 hover:text-blue-700 hover:underline">
+              Back to Login
+            </a>
+          </p>
+        </div>
+      </motion.div>
+    </div>
+  );
+}
+
+// useSearchParams needs a Suspense boundary so the page can still be prerendered
+export default function ResetPasswordPage() {
+  return (
+    <Suspense>
+      <ResetPasswordForm />
+    </Suspense>
+  );
+}
@@ -15,7 +15,6 @@ interface AddNovelModalProps {
 const AddNovelModal: React.FC<AddNovelModalProps> = ({ isOpen, onClose, onSuccess, novel }) => {
     const [title, setTitle] = useState('');
     const [author, setAuthor] = useState('');
-    const [rating, setRating] = useState(0);
     const [language, setLanguage] = useState('');
     const [yearPublished, setYearPublished] = useState(new Date().getFullYear());
     const [loading, setLoading] = useState(false);
@@ -27,13 +26,11 @@ const AddNovelModal: React.FC<AddNovelModalProps> = ({ isOpen, onClose, onSucces
         if (novel) {
             setTitle(novel.title);
             setAuthor(novel.author);
-            setRating(novel.rating);
             setLanguage(novel.language);
             setYearPublished(novel.year_published);
         } else {
             setTitle('');
             setAuthor('');
-            setRating(0);
             setLanguage('');
             setYearPublished(new Date().getFullYear());
         }
@@ -50,7 +47,6 @@ const AddNovelModal: React.FC<AddNovelModalProps> = ({ isOpen, onClose, onSucces
             const novelData = {
                 title,
                 author,
-                rating: Number(rating),
                 language,
                 year_published: Number(yearPublished),
             };
@@ -68,8 +64,7 @@ const AddNovelModal: React.FC<AddNovelModalProps> = ({ isOpen, onClose, onSucces
                 if (!novel) {
                     setTitle('');
                     setAuthor('');
-                    setRating(0);
-                    setLanguage('');
+                            setLanguage('');
                     setYearPublished(new Date().getFullYear());
                 }
                 if (onSuccess) onSuccess();
@@ -139,21 +134,6 @@ const AddNovelModal: React.FC<AddNovelModalProps> = ({ isOpen, onClose, onSucces
                                     />
                                 </div>
 
-                                <div className="mb-4">
-                                    <label className="block text-sm font-medium text-slate-700 mb-1">Rating</label>
-                                    <input
-                                        type="number"
-                                        step="0.1"
-                                        min="0"
-                                        max="5"
-                                        value={rating}
-                                        onChange={(e) => setRating(parseFloat(e.target.value))}
-                                        required
-                                        className="w-full px-4 py-2.5 bg-gray-50 border border-gray-200 rounded-xl text-slate-800 text-sm focus:bg-white focus:border-blue-500 focus:ring-4 focus:ring-blue-500/10 transition-all outline-none"
-                                        placeholder="Enter rating (0-5)"
-                                    />
-                                </div>
-
                                 <div className="mb-4">
                                     <label className="block text-sm font-medium text-slate-700 mb-1">Language</label>
                                     <input
@@ -11,6 +11,10 @@ interface User {
   name: string;
   email: string;
   role: string;
+  permissions?: string[];
+  bio?: string;
+  avatar_url?: string;
+  email_verified?: boolean;
 }
 
 interface AuthContextType {
@@ -66,7 +70,14 @@ export function AuthProvider({ children }: { children: ReactNode }) {
   };
 
   // 3. Fungsi Logout
-  const logout = () => {
+  const logout = async () => {
+    // Revoke refresh token di server, lanjut logout lokal walaupun request gagal
+    try {
+      await novelService.logout();
+    } catch (error) {
+      console.log("Server logout failed");
+    }
+
     setToken(null);
     setUser(null);
     Cookies.remove("token");
@@ -0,0 +1,41 @@
+import { csrfHeaders } from './csrf';
+
+const API_URL = 'http://localhost:8001';
+
+// Only one refresh at a time: sending the same refresh token twice counts as reuse
+// and the API would log every device out
+let refreshing: Promise<boolean> | null = null;
+
+function refreshSession(): Promise<boolean> {
+    if (!refreshing) {
+        refreshing = fetch(`${API_URL}/auth/refresh`, {
+            method: 'POST',
+            headers: csrfHeaders(),
+            credentials: 'include',
+        })
+            .then((res) => res.ok)
+            .catch(() => false)
+            .finally(() => {
+                refreshing = null;
+            });
+    }
+    return refreshing;
+}
+
+// authFetch sends the auth cookies and CSRF header. Access tokens are short-lived, so on a 401
+// it trades the refresh_token cookie for new ones (POST /auth/refresh) and retries once.
+export async function authFetch(url: string, init: RequestInit = {}): Promise<Response> {
+    const send = () =>
+        fetch(url, {
+            ...init,
+            // Read the CSRF cookie again, the refresh replaces it
+            headers: { ...(init.headers as Record<string, string>), ...csrfHeaders() },
+            credentials: 'include',
+        });
+
+    const res = await send();
+    if (res.status !== 401 || !(await refreshSession())) {
+        return res;
+    }
+    return send();
+}
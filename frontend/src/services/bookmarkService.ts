@@ -1,4 +1,5 @@
 import { Novel } from '@/types';
+import { authFetch } from './authFetch';
 
 const API_URL = 'http://localhost:8001';
 
@@ -9,9 +10,8 @@ export const bookmarkService = {
             headers['Authorization'] = `Bearer ${token}`;
         }
 
-        const res = await fetch(`${API_URL}/bookmarks`, {
+        const res = await authFetch(`${API_URL}/bookmarks`, {
             headers,
-            credentials: 'include', // Important for sending cookies
         });
 
         if (!res.ok) {
@@ -29,10 +29,9 @@ export const bookmarkService = {
             headers['Authorization'] = `Bearer ${token}`;
         }
 
-        const res = await fetch(`${API_URL}/bookmarks/${novelId}`, {
+        const res = await authFetch(`${API_URL}/bookmarks/${novelId}`, {
             method: 'POST',
             headers,
-            credentials: 'include',
         });
 
         if (!res.ok) {
@@ -46,10 +45,9 @@ export const bookmarkService = {
             headers['Authorization'] = `Bearer ${token}`;
         }
 
-        const res = await fetch(`${API_URL}/bookmarks/${novelId}`, {
+        const res = await authFetch(`${API_URL}/bookmarks/${novelId}`, {
             method: 'DELETE',
             headers,
-            credentials: 'include',
         });
 
         if (!res.ok) {
@@ -0,0 +1,7 @@
+// The API sets a readable "csrf_token" cookie at login. Requests that rely on the auth cookie
+// (no Bearer token) must echo it in the X-CSRF-Token header for POST/PUT/PATCH/DELETE.
+export function csrfHeaders(): Record<string, string> {
+    if (typeof document === 'undefined') return {};
+    const match = document.cookie.match(/(?:^|;\s*)csrf_token=([^;]+)/);
+    return match ? { 'X-CSRF-Token': decodeURIComponent(match[1]) } : {};
+}
@@ -1,16 +1,18 @@
+import { authFetch } from './authFetch';
+import { csrfHeaders } from './csrf';
+
 const API_URL = 'http://localhost:8001';
 
 export interface NovelData {
     title: string;
     author: string;
-    rating: number;
     language: string;
     year_published: number;
 }
 
 export const novelService = {
     async getNovels(): Promise<any> {
-        const res = await fetch(`${API_URL}/novels`);
+        const res = await fetch(`${API_URL}/novels?page_size=100`);
         if (!res.ok) {
             throw new Error('Failed to fetch novels');
         }
@@ -25,11 +27,10 @@ export const novelService = {
             headers['Authorization'] = `Bearer ${token}`;
         }
 
-        const res = await fetch(`${API_URL}/novels`, {
+        const res = await authFetch(`${API_URL}/novels`, {
             method: 'POST',
             headers,
             body: JSON.stringify(novelData),
-            credentials: 'include',
         });
 
         if (!res.ok) {
@@ -46,11 +47,10 @@ export const novelService = {
             headers['Authorization'] = `Bearer ${token}`;
         }
 
-        const res = await fetch(`${API_URL}/novels/${id}`, {
+        const res = await authFetch(`${API_URL}/novels/${id}`, {
             method: 'PUT',
             headers,
             body: JSON.stringify(novelData),
-            credentials: 'include',
         });
 
         if (!res.ok) {
@@ -65,10 +65,9 @@ export const novelService = {
             headers['Authorization'] = `Bearer ${token}`;
         }
 
-        const res = await fetch(`${API_URL}/novels/${id}`, {
+        const res = await authFetch(`${API_URL}/novels/${id}`, {
             method: 'DELETE',
             headers,
-            credentials: 'include',
         });
 
         if (!res.ok) {
@@ -83,9 +82,8 @@ export const novelService = {
             headers['Authorization'] = `Bearer ${token}`;
         }
 
-        const res = await fetch(`${API_URL}/profile`, {
+        const res = await authFetch(`${API_URL}/profile`, {
             headers,
-            credentials: 'include',
         });
 
         if (!res.ok) {
@@ -93,4 +91,17 @@ export const novelService = {
         }
         return res.json();
     },
+
+    async logout(): Promise<void> {
+        // The refresh and access tokens travel in cookies here, so the API wants the CSRF header too
+        const res = await fetch(`${API_URL}/logout`, {
+            method: 'POST',
+            headers: csrfHeaders(),
+            credentials: 'include',
+        });
+
+        if (!res.ok) {
+            throw new Error('Failed to logout');
+        }
+    },
 };
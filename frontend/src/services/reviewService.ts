@@ -1,3 +1,5 @@
+import { authFetch } from './authFetch';
+
 const API_URL = 'http://localhost:8001';
 
 export const reviewService = {
@@ -9,10 +11,9 @@ export const reviewService = {
             headers['Authorization'] = `Bearer ${token}`;
         }
 
-        const res = await fetch(`${API_URL}/novels/${novelId}/reviews`, {
+        const res = await authFetch(`${API_URL}/novels/${novelId}/reviews`, {
             method: 'POST',
             headers,
-            credentials: 'include',
             body: JSON.stringify({
                 rating: rating,
                 comment: comment,
@@ -33,10 +34,9 @@ export const reviewService = {
             headers['Authorization'] = `Bearer ${token}`;
         }
 
-        const res = await fetch(`${API_URL}/novels/${novelId}/reviews`, {
+        const res = await authFetch(`${API_URL}/novels/${novelId}/reviews`, {
             method: 'PUT',
             headers,
-            credentials: 'include',
             body: JSON.stringify({
                 rating: rating,
                 comment: comment,
@@ -67,9 +67,8 @@ export const reviewService = {
                 headers['Authorization'] = `Bearer ${token}`;
             }
 
-            const res = await fetch(`${API_URL}/novels/${novelId}/reviews/user`, {
+            const res = await authFetch(`${API_URL}/novels/${novelId}/reviews/user`, {
                 headers,
-                credentials: 'include',
             });
 
             if (res.status === 404) {
@@ -3,8 +3,26 @@ export interface Novel {
   title: string;
   author: string;
   rating: number;
+  average_rating: number;
+  review_count: number;
+  rating_histogram: Record<string, number>;
   language: string;
   year_published: number;
+  genres?: Genre[];
+  tags?: Tag[];
+  progress?: ReadingProgress | null;
+}
+
+export interface Genre {
+  id: number;
+  name: string;
+  slug: string;
+  novel_count?: number;
+}
+
+export interface Tag {
+  id: number;
+  name: string;
 }
 
 export interface Review {
@@ -15,4 +33,12 @@ export interface Review {
   rating: number;
   createdAt: string;
   updatedAt: string;
-}
\ No newline at end of file
+}
+export interface ReadingProgress {
+  id: number;
+  novel_id: number;
+  status: 'want_to_read' | 'reading' | 'completed' | 'dropped';
+  last_chapter: number | null;
+  percentage: number;
+  last_read_at: string | null;
+}
@@ -3,17 +3,43 @@ package initializers
 import (
 	"log"
 	"os"
+	"strings"
 
+	"github.com/glebarez/sqlite"
 	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
 
 var DB *gorm.DB
 
+// ConnectToDB opens DB_URL with the driver selected by DB_DRIVER (mysql, postgres or sqlite; default mysql).
+// For sqlite DB_URL is a file path, e.g. "library.db".
 func ConnectToDB() {
 	var err error
 	dsn := os.Getenv("DB_URL")
-	DB, err = gorm.Open(mysql.Open(dsn), &gorm.Config{})
+
+	var dialector gorm.Dialector
+	switch driver := strings.ToLower(os.Getenv("DB_DRIVER")); driver {
+	case "", "mysql":
+		dialector = mysql.Open(dsn)
+	case "postgres", "postgresql":
+		dialector = postgres.Open(dsn)
+	case "sqlite", "sqlite3":
+		// SQLite leaves foreign keys off unless asked, and the cascades in the schema rely on them
+		if !strings.Contains(dsn, "_pragma=foreign_keys") {
+			if strings.Contains(dsn, "?") {
+				dsn += "&_pragma=foreign_keys(1)"
+			} else {
+				dsn += "?_pragma=foreign_keys(1)"
+			}
+		}
+		dialector = sqlite.Open(dsn)
+	default:
+		log.Fatalf("Unsupported DB_DRIVER %q, use mysql, postgres or sqlite", driver)
+	}
+
+	DB, err = gorm.Open(dialector, &gorm.Config{})
 	if err != nil {
 		log.Fatalf("Error connecting to database: %v", err)
 	}
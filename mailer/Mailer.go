@@ -0,0 +1,135 @@
+package mailer
+
+import (
+	"fmt"
+	"log"
+	"net/smtp"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Mailer sends a plain text email. Pick the implementation with MAILER=smtp|file|log (default log).
+type Mailer interface {
+	Send(to, subject, body string) error
+}
+
+var (
+	current Mailer
+	mu      sync.RWMutex
+)
+
+// Init builds the mailer from the environment, call it after LoadEnvVariables
+func Init() {
+	var m Mailer
+	switch strings.ToLower(os.Getenv("MAILER")) {
+	case "smtp":
+		m = &SMTPMailer{
+			Host:     os.Getenv("SMTP_HOST"),
+			Port:     envOrDefault("SMTP_PORT", "587"),
+			Username: os.Getenv("SMTP_USER"),
+			Password: os.Getenv("SMTP_PASSWORD"),
+			From:     envOrDefault("MAIL_FROM", "no-reply@localhost"),
+		}
+	case "file":
+		m = &FileMailer{Path: envOrDefault("MAIL_FILE", "mail.log")}
+	case "", "log":
+		m = &LogMailer{}
+	default:
+		log.Fatalf("Unsupported MAILER %q, use smtp, file or log", os.Getenv("MAILER"))
+	}
+	Set(m)
+}
+
+// Set replaces the mailer in use (handy for a custom implementation)
+func Set(m Mailer) {
+	mu.Lock()
+	defer mu.Unlock()
+	current = m
+}
+
+// Send delivers an email with the configured mailer, falling back to the log mailer if Init was not called
+func Send(to, subject, body string) error {
+	mu.RLock()
+	m := current
+	mu.RUnlock()
+	if m == nil {
+		m = &LogMailer{}
+	}
+	return m.Send(to, subject, body)
+}
+
+// SendAsync sends in the background and only logs failures, so the response time of a
+// request does not reveal whether an email was actually sent
+func SendAsync(to, subject, body string) {
+	go func() {
+		if err := Send(to, subject, body); err != nil {
+			log.Printf("mailer: could not send %q to %s: %v", subject, to, err)
+		}
+	}()
+}
+
+// SMTPMailer sends through an SMTP server with PLAIN auth (STARTTLS is used when the server offers it)
+type SMTPMailer struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+func (m *SMTPMailer) Send(to, subject, body string) error {
+	var auth smtp.Auth
+	if m.Username != "" {
+		auth = smtp.PlainAuth("", m.Username, m.Password, m.Host)
+	}
+	return smtp.SendMail(m.Host+":"+m.Port, auth, m.From, []string{to}, buildMessage(m.From, to, subject, body))
+}
+
+// FileMailer appends every email to a file, for local development
+type FileMailer struct {
+	Path string
+	mu   sync.Mutex
+}
+
+func (m *FileMailer) Send(to, subject, body string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	f, err := os.OpenFile(m.Path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = fmt.Fprintf(f, "%s\n%s\n", buildMessage(envOrDefault("MAIL_FROM", "no-reply@localhost"), to, subject, body), strings.Repeat("-", 72))
+	return err
+}
+
+// LogMailer prints emails to the server log instead of sending them
+type LogMailer struct{}
+
+func (m *LogMailer) Send(to, subject, body string) error {
+	log.Printf("mailer: to=%s subject=%q\n%s", to, subject, body)
+	return nil
+}
+
+func buildMessage(from, to, subject, body string) []byte {
+	headers := []string{
+		"From: " + from,
+		"To: " + to,
+		"Subject: " + subject,
+		"Date: " + time.Now().Format(time.RFC1123Z),
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=UTF-8",
+	}
+	return []byte(strings.Join(headers, "\r\n") + "\r\n\r\n" + body + "\r\n")
+}
+
+func envOrDefault(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
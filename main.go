@@ -1,10 +1,17 @@
 package main
 
 import (
+	"log"
+
 	"github.com/Algoritma-dan-Pemrograman-ITS/Framework-Programming-GIN-GORM/controllers"
 	"github.com/Algoritma-dan-Pemrograman-ITS/Framework-Programming-GIN-GORM/initializers"
+	"github.com/Algoritma-dan-Pemrograman-ITS/Framework-Programming-GIN-GORM/mailer"
 	"github.com/Algoritma-dan-Pemrograman-ITS/Framework-Programming-GIN-GORM/middleware"
+	"github.com/Algoritma-dan-Pemrograman-ITS/Framework-Programming-GIN-GORM/migrations"
 	"github.com/Algoritma-dan-Pemrograman-ITS/Framework-Programming-GIN-GORM/models"
+	"github.com/Algoritma-dan-Pemrograman-ITS/Framework-Programming-GIN-GORM/oidc"
+	"github.com/Algoritma-dan-Pemrograman-ITS/Framework-Programming-GIN-GORM/search"
+	"github.com/Algoritma-dan-Pemrograman-ITS/Framework-Programming-GIN-GORM/utils"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 )
@@ -12,58 +19,149 @@ import (
 func init() {
 	initializers.LoadEnvVariables()
 	initializers.ConnectToDB()
-	initializers.DB.AutoMigrate(&models.User{}, &models.Novel{}, models.Review{})
+	mailer.Init()
+	oidc.Init()
+	if err := search.Init(); err != nil {
+		log.Fatalf("Error opening search index: %v", err)
+	}
+	if err := utils.LoadSigningKeys(); err != nil {
+		log.Fatalf("Error loading JWT signing keys: %v", err)
+	}
+
+	// Schema changes are applied with `go run migrate/Migrate.go up`, never on server start
+	if err := migrations.CheckUpToDate(initializers.DB); err != nil {
+		log.Fatal(err)
+	}
+	if err := controllers.SyncSearchIndex(); err != nil {
+		log.Fatalf("Error building search index: %v", err)
+	}
 }
 
 func main() {
 	router := gin.Default()
+	// Client IPs feed the login throttle, so X-Forwarded-For only counts when it comes from a known proxy
+	if err := router.SetTrustedProxies(utils.TrustedProxies()); err != nil {
+		log.Fatalf("Invalid TRUSTED_PROXIES: %v", err)
+	}
 
 	router.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"http://localhost:3000", "http://127.0.0.1:5500", "http://localhost:5500"},
-		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization"},
+		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization", "X-API-Key", "X-CSRF-Token"},
 		ExposeHeaders:    []string{"Content-Length"},
 		AllowCredentials: true,
 	}))
 
+	// Public keys for verifying access tokens (RS256/EdDSA)
+	router.GET("/.well-known/jwks.json", controllers.GetJWKS)
 	//Use Raw JSON POST with param: "name", "email", "password"
 	router.POST("/register", controllers.Register)
 	//Use Raw JSON POST with param: "email", "password"
 	router.POST("/login", controllers.Login)
+	//Second login step when 2FA is on. Use Raw JSON POST with param: "mfa_token", "code" or "recovery_code"
+	router.POST("/login/2fa", controllers.LoginTwoFactor)
+	//Uses the "refresh_token" cookie, or Raw JSON POST with param: "refresh_token"
+	router.POST("/auth/refresh", controllers.Refresh)
+	router.POST("/logout", controllers.Logout)
+	// Login with an external OpenID Connect / OAuth2 provider (see OIDC_PROVIDERS)
+	router.GET("/auth/oidc", controllers.GetOIDCProviders)                // <-- configured provider names
+	router.GET("/auth/oidc/:provider", controllers.StartOIDCLogin)        // <-- optional ?return_to=/path on the frontend
+	router.GET("/auth/oidc/:provider/callback", controllers.OIDCCallback) // <-- answers like /login
+	//Use Raw JSON POST with param: "email" (always answers 200)
+	router.POST("/password/forgot", controllers.ForgotPassword)
+	//Use Raw JSON POST with param: "token", "password"
+	router.POST("/password/reset", controllers.ResetPassword)
+	//Example: localhost:8001/verify-email?token=... (link from the verification email)
+	router.GET("/verify-email", controllers.VerifyEmail)
+	//Example: localhost:8001/profile/email/confirm?token=... (link sent to the new address)
+	router.GET("/profile/email/confirm", controllers.ConfirmEmailChange)
 
 	//Example: localhost:8001/novels
 	router.GET("/novels", controllers.GetAllNovels)
+	//Example: localhost:8001/search?q=tolkin&language=english&year=1937 (ranked, typo tolerant, with facets)
+	router.GET("/search", controllers.SearchNovels)
 	//Example: localhost:8001/novels/1
 	router.GET("/novels/:id", controllers.GetNovelByID)
 	// Reviews routes
 	router.GET("/novels/:id/reviews", controllers.GetReviewsByNovel)
+	// Chapters: table of contents and reading (published chapters only)
+	router.GET("/novels/:id/chapters", controllers.GetChapters)
+	router.GET("/novels/:id/chapters/:number", controllers.GetChapter)
+	//Example: localhost:8001/genres (includes novel_count per genre)
+	router.GET("/genres", controllers.GetGenres)
 
 	//In postman: Login then enter auth code in "Authorization" with type "Bearer Token"
-	// Admin only routes
+	// Staff routes are guarded by permissions, see models.RolePermissions
 	protected := router.Group("/")
 	protected.Use(middleware.AuthMiddleware)
 	{
-		admin := protected.Group("/")
-		admin.Use(middleware.AdminOnly)
-		{
-			admin.POST("/novels", controllers.CreateNovel)
-			admin.PUT("/novels/:id", controllers.UpdateNovel)
-			admin.DELETE("/novels/:id", controllers.RemoveNovel)
-			admin.DELETE("/users/:id", controllers.DeleteUser)
-		}
+		// Editors and admins
+		protected.POST("/novels", middleware.RequirePermission(models.PermNovelWrite), controllers.CreateNovel)
+		protected.POST("/novels/import", middleware.RequirePermission(models.PermNovelWrite), controllers.ImportNovels) // <-- CSV or JSON lines, ?dry_run=true
+		protected.PUT("/novels/:id", middleware.RequirePermission(models.PermNovelWrite), controllers.UpdateNovel)
+		protected.GET("/novels/:id/history", middleware.RequirePermission(models.PermNovelWrite), controllers.GetNovelHistory) // <-- revisions with changed fields and who made them
+		protected.POST("/novels/:id/chapters", middleware.RequirePermission(models.PermNovelWrite), controllers.CreateChapter)
+		protected.PUT("/novels/:id/chapters/:number", middleware.RequirePermission(models.PermNovelWrite), controllers.UpdateChapter)
+		protected.POST("/genres", middleware.RequirePermission(models.PermGenreWrite), controllers.CreateGenre)
+		protected.PUT("/genres/:id", middleware.RequirePermission(models.PermGenreWrite), controllers.UpdateGenre)
+		protected.DELETE("/genres/:id", middleware.RequirePermission(models.PermGenreWrite), controllers.DeleteGenre)
+		// Admins only
+		protected.DELETE("/novels/:id", middleware.RequirePermission(models.PermNovelDelete), controllers.RemoveNovel) // <-- moves it to the trash
+		protected.POST("/novels/:id/restore", middleware.RequirePermission(models.PermNovelDelete), controllers.RestoreNovel)
+		protected.GET("/admin/trash", middleware.RequirePermission(models.PermNovelDelete), controllers.GetTrash)                             // <-- removed novels and when they are purged
+		protected.POST("/novels/:id/history/:revision/revert", middleware.RequirePermission(models.PermNovelRevert), controllers.RevertNovel) // <-- back to the state right after that revision
+		protected.DELETE("/novels/:id/chapters/:number", middleware.RequirePermission(models.PermNovelDelete), controllers.DeleteChapter)
+		protected.DELETE("/users/:id", middleware.RequirePermission(models.PermUserDelete), controllers.DeleteUser)
+		protected.PATCH("/users/:id", middleware.RequirePermission(models.PermUserManage), controllers.AssignRole)    // <-- "role", same as PUT /users/:id/role
+		protected.GET("/admin/roles", middleware.RequirePermission(models.PermUserRead), controllers.GetRoles)        // <-- roles and their permissions
+		protected.PUT("/users/:id/role", middleware.RequirePermission(models.PermUserManage), controllers.AssignRole) // <-- "role": admin|moderator|editor|user
+		// Moderators and admins
+		//Example: localhost:8001/users?q=alice&role=user&suspended=true&page=1&sort=-id
+		protected.GET("/users", middleware.RequirePermission(models.PermUserRead), controllers.GetUsers)
+		protected.GET("/users/:id", middleware.RequirePermission(models.PermUserRead), controllers.GetUser)                 // <-- includes review_count and bookmark_count
+		protected.POST("/users/:id/suspend", middleware.RequirePermission(models.PermUserSuspend), controllers.SuspendUser) // <-- optional "reason", logs the user out everywhere
+		protected.POST("/users/:id/unsuspend", middleware.RequirePermission(models.PermUserSuspend), controllers.UnsuspendUser)
+		protected.GET("/admin/lockouts", middleware.RequirePermission(models.PermLockoutManage), controllers.GetLockouts)         // <-- ?locked=true&kind=account|ip
+		protected.DELETE("/admin/lockouts/:id", middleware.RequirePermission(models.PermLockoutManage), controllers.ClearLockout) // <-- unlock an account or IP
+		protected.GET("/admin/export", middleware.RequirePermission(models.PermCatalogExport), controllers.ExportCatalog)         // <-- ?type=novels|reviews&format=csv|json|ndjson plus the /novels filters
 
 		//localhost:8001/profile/
-		protected.GET("/profile", controllers.Profile) // <-- profile includes: id, name, and email
+		protected.GET("/profile", controllers.Profile)                                        // <-- profile includes: id, name, and email
+		protected.PATCH("/profile", controllers.UpdateProfile)                                // <-- "name", "bio", "avatar_url"
+		protected.POST("/profile/email", middleware.NoAPIKey, controllers.RequestEmailChange) // <-- "email", "password", mails a confirmation link
+		protected.POST("/profile/password", middleware.NoAPIKey, controllers.ChangePassword)  // <-- "current_password", "new_password"
+		// Personal API keys, sent as "X-API-Key: dl_..." instead of a Bearer token
+		protected.GET("/profile/api-keys", middleware.NoAPIKey, controllers.GetAPIKeys)
+		protected.POST("/profile/api-keys", middleware.NoAPIKey, controllers.CreateAPIKey)       // <-- "name", "scopes", "expires_in_days"; the key is shown once
+		protected.DELETE("/profile/api-keys/:id", middleware.NoAPIKey, controllers.RevokeAPIKey) // <-- revoke a key
+		// Logged in devices
+		protected.GET("/profile/sessions", middleware.NoAPIKey, controllers.GetSessions)          // <-- devices you are logged in on, "current" marks this one
+		protected.DELETE("/profile/sessions/:id", middleware.NoAPIKey, controllers.RevokeSession) // <-- log that device out
+		protected.POST("/verify-email/resend", controllers.ResendVerificationEmail)
+		// Two-factor authentication (TOTP)
+		protected.POST("/2fa/setup", middleware.NoAPIKey, controllers.SetupTwoFactor)                   // <-- returns secret + otpauth:// URI
+		protected.POST("/2fa/enable", middleware.NoAPIKey, controllers.EnableTwoFactor)                 // <-- "code", returns recovery codes
+		protected.POST("/2fa/disable", middleware.NoAPIKey, controllers.DisableTwoFactor)               // <-- "password", "code" or "recovery_code"
+		protected.POST("/2fa/recovery-codes", middleware.NoAPIKey, controllers.RegenerateRecoveryCodes) // <-- "code"
+		//localhost:8001/logout-all
+		protected.POST("/logout-all", controllers.LogoutAll) // <-- revoke sessions on every device
 		//localhost:8001/bookmarks
 		protected.GET("/bookmarks", controllers.GetBookmarkedNovels)         // <-- get all bookmark
 		protected.POST("/bookmarks/:novel_id", controllers.BookmarkNovel)    // <-- add a novel to bookmark
 		protected.DELETE("/bookmarks/:novel_id", controllers.RemoveBookmark) // <-- remove novel from bookmark
+		//localhost:8001/progress?status=reading
+		protected.GET("/progress", controllers.GetReadingProgress)                 // <-- reading progress, most recent first
+		protected.PUT("/progress/:novel_id", controllers.UpdateReadingProgress)    // <-- "status", "last_chapter", "percentage"
+		protected.DELETE("/progress/:novel_id", controllers.RemoveReadingProgress) // <-- forget progress for a novel
 		// Reviews routes
-		protected.POST("/novels/:novelID/reviews", controllers.CreateReview)
+		protected.POST("/novels/:id/reviews", middleware.VerifiedEmailRequired, controllers.CreateReview)
 		protected.GET("/reviews/:reviewID", controllers.GetReviewByID)
 		protected.PUT("/reviews/:reviewID", controllers.UpdateReview)
-		protected.DELETE("/reviews/:reviewID", controllers.DeleteReview)
+		protected.DELETE("/reviews/:reviewID", controllers.DeleteReview) // <-- author, or anyone with review:moderate
 	}
 
+	// Novels removed longer than TRASH_RETENTION ago are deleted for good
+	controllers.StartTrashPurger()
+
 	router.Run(":8001")
 }
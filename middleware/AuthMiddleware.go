@@ -2,19 +2,30 @@ package middleware
 
 import (
 	"net/http"
-	"os"
-	"strconv"
+	"time"
 
 	"github.com/Algoritma-dan-Pemrograman-ITS/Framework-Programming-GIN-GORM/initializers"
 	"github.com/Algoritma-dan-Pemrograman-ITS/Framework-Programming-GIN-GORM/models"
+	"github.com/Algoritma-dan-Pemrograman-ITS/Framework-Programming-GIN-GORM/utils"
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v4"
 )
 
 func AuthMiddleware(c *gin.Context) {
+	// Scripts authenticate with a personal API key instead of a session
+	if rawKey := c.GetHeader("X-API-Key"); rawKey != "" {
+		authenticateAPIKey(c, rawKey)
+		return
+	}
+
 	// Prefer token in cookie named "token". If missing, fall back to Authorization header.
+	// Browsers send the cookie on their own, so it also needs the CSRF token on unsafe requests.
 	tokenString, err := c.Cookie("token")
-	if err != nil || tokenString == "" {
+	if err == nil && tokenString != "" {
+		if !utils.ValidCSRF(c) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Missing or invalid CSRF token"})
+			return
+		}
+	} else {
 		// Try to get from Authorization header
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
@@ -30,44 +41,25 @@ func AuthMiddleware(c *gin.Context) {
 		}
 	}
 
-	secret := os.Getenv("JWT_SECRET")
-
-	token, err := jwt.Parse(tokenString, func(t *jwt.Token) (interface{}, error) {
-		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, jwt.ErrSignatureInvalid
-		}
-		return []byte(secret), nil
-	})
-
-	if err != nil || !token.Valid {
+	claims, err := utils.ParseAccessToken(tokenString)
+	if err != nil {
 		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
 		return
 	}
 
-	claims, ok := token.Claims.(jwt.MapClaims)
-	if !ok {
-		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid token claims"})
-		return
-	}
-
-	sub, ok := claims["sub"]
-	if !ok {
-		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid token subject"})
+	if isFamilyRevoked(claims.FamilyID) {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Session has been logged out"})
 		return
 	}
 
-	// sub is numeric (float64) or string; handle both
-	var id uint64
-	parsed, err := strconv.ParseUint(sub.(string), 10, 64)
-	if err != nil {
-		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid token subject"})
+	var user models.User
+	if result := initializers.DB.First(&user, claims.UserID); result.Error != nil {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
 		return
 	}
-	id = parsed
 
-	var user models.User
-	if result := initializers.DB.First(&user, id); result.Error != nil {
-		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+	if user.IsSuspended() {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Account suspended"})
 		return
 	}
 
@@ -75,60 +67,114 @@ func AuthMiddleware(c *gin.Context) {
 	c.Next()
 }
 
-func AuthMiddleware2(c *gin.Context) {
-	// Prefer token in cookie named "token". If missing, fall back to Authorization header.
-	tokenString, err := c.Cookie("token")
-	if err != nil || tokenString == "" {
-		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Authorization cookie required"})
+// authenticateAPIKey loads the owner of an X-API-Key and checks the key's scopes against the request method
+func authenticateAPIKey(c *gin.Context, rawKey string) {
+	var key models.APIKey
+	if result := initializers.DB.First(&key, "key_hash = ?", utils.HashToken(rawKey)); result.Error != nil {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid API key"})
 		return
 	}
 
-	secret := os.Getenv("JWT_SECRET")
-
-	token, err := jwt.Parse(tokenString, func(t *jwt.Token) (interface{}, error) {
-		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, jwt.ErrSignatureInvalid
-		}
-		return []byte(secret), nil
-	})
-
-	if err != nil || !token.Valid {
-		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+	now := time.Now()
+	if key.RevokedAt != nil || (key.ExpiresAt != nil && now.After(*key.ExpiresAt)) {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "API key revoked or expired"})
 		return
 	}
 
-	claims, ok := token.Claims.(jwt.MapClaims)
-	if !ok {
-		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid token claims"})
+	scope := models.ScopeWrite
+	if c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead {
+		scope = models.ScopeRead
+	}
+	if !key.Allows(scope) {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "API key lacks the " + scope + " scope"})
 		return
 	}
 
-	sub, ok := claims["sub"]
-	if !ok {
-		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid token subject"})
+	var user models.User
+	if result := initializers.DB.First(&user, key.UserID); result.Error != nil {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
 		return
 	}
 
-	// sub is numeric (float64) or string; handle both
-	var id uint64
-	parsed, err := strconv.ParseUint(sub.(string), 10, 64)
-	if err != nil {
-		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid token subject"})
+	if user.IsSuspended() {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Account suspended"})
 		return
 	}
-	id = parsed
 
-	var user models.User
-	if result := initializers.DB.First(&user, id); result.Error != nil {
-		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
-		return
+	// Minute precision is enough and saves a write on every scripted request
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) > time.Minute {
+		initializers.DB.Model(&key).Update("last_used_at", now)
 	}
 
 	c.Set("user", user)
+	c.Set("api_key", key)
+	c.Next()
+}
+
+// NoAPIKey refuses requests authenticated with an API key, for routes that manage credentials
+func NoAPIKey(c *gin.Context) {
+	if _, exists := c.Get("api_key"); exists {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Not available with an API key, please login"})
+		return
+	}
 	c.Next()
 }
 
-func AdminOnly(c *gin.Context) {
+// isFamilyRevoked reports whether the session behind an access token was logged out.
+// A session that cannot be loaded (deleted, or a database error) counts as revoked.
+// It also refreshes the session's last_seen_at, at most once a minute to keep writes down.
+func isFamilyRevoked(familyID string) bool {
+	if familyID == "" {
+		return false
+	}
+
+	var session models.Session
+	if result := initializers.DB.Where("family_id = ?", familyID).Limit(1).Find(&session); result.Error != nil || result.RowsAffected == 0 {
+		return true
+	}
+	if session.RevokedAt != nil {
+		return true
+	}
+
+	if time.Since(session.LastSeenAt) > time.Minute {
+		initializers.DB.Model(&session).Update("last_seen_at", time.Now())
+	}
+	return false
+}
+
+// RequirePermission only lets the request through when utils.CheckPermission allows permission.
+// Use after AuthMiddleware.
+func RequirePermission(permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		u, exists := c.Get("user")
+		if !exists {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+			return
+		}
+
+		user, ok := u.(models.User)
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "User context error"})
+			return
+		}
+
+		if errMsg := utils.CheckPermission(c, user, permission); errMsg != "" {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": errMsg})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// VerifiedEmailRequired blocks unverified users when EMAIL_VERIFICATION_POLICY is "reviews" or "login".
+// Use after AuthMiddleware on routes that post user content (reviews).
+func VerifiedEmailRequired(c *gin.Context) {
+	if utils.EmailVerificationPolicy() == utils.EmailVerificationNone {
+		c.Next()
+		return
+	}
+
 	u, exists := c.Get("user")
 	if !exists {
 		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
@@ -141,8 +187,8 @@ func AdminOnly(c *gin.Context) {
 		return
 	}
 
-	if user.Role != "admin" {
-		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Access forbidden: Admins only"})
+	if !user.EmailVerified {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Please verify your email address first"})
 		return
 	}
 
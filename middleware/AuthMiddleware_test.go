@@ -0,0 +1,104 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Algoritma-dan-Pemrograman-ITS/Framework-Programming-GIN-GORM/initializers"
+	"github.com/Algoritma-dan-Pemrograman-ITS/Framework-Programming-GIN-GORM/models"
+	"github.com/Algoritma-dan-Pemrograman-ITS/Framework-Programming-GIN-GORM/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// loggedIn creates a user with one session and returns an access token for it
+func loggedIn(t *testing.T) (models.Session, string) {
+	t.Helper()
+	t.Setenv("JWT_SECRET", "test-secret")
+
+	user := models.User{Name: "Alice", Email: "alice@example.com"}
+	if err := initializers.DB.Create(&user).Error; err != nil {
+		t.Fatal(err)
+	}
+	session := models.Session{UserID: user.ID, FamilyID: "family-1", LastSeenAt: time.Now()}
+	if err := initializers.DB.Create(&session).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	token, err := utils.GenerateAccessToken(user.ID, session.FamilyID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return session, token
+}
+
+// authenticate runs AuthMiddleware on req and returns the status it answered with (200 when it let the request through)
+func authenticate(req *http.Request) int {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+	AuthMiddleware(c)
+	if _, exists := c.Get("user"); !exists && !c.IsAborted() {
+		return http.StatusInternalServerError
+	}
+	return w.Code
+}
+
+func TestAuthMiddlewarePrefersCookieOverHeader(t *testing.T) {
+	setupTestDB(t)
+	_, token := loggedIn(t)
+
+	// After a reload the frontend only has a placeholder to put in the Authorization header
+	request := func(method, csrf string) *http.Request {
+		req := httptest.NewRequest(method, "/bookmarks", nil)
+		req.Header.Set("Authorization", "Bearer cookie-auth")
+		req.AddCookie(&http.Cookie{Name: "token", Value: token})
+		req.AddCookie(&http.Cookie{Name: utils.CSRFCookie, Value: "csrf-1"})
+		if csrf != "" {
+			req.Header.Set(utils.CSRFHeader, csrf)
+		}
+		return req
+	}
+
+	if code := authenticate(request(http.MethodGet, "")); code != http.StatusOK {
+		t.Errorf("GET with cookie and bogus header: status %d, want 200", code)
+	}
+	if code := authenticate(request(http.MethodPost, "")); code != http.StatusForbidden {
+		t.Errorf("POST with cookie but no CSRF header: status %d, want 403", code)
+	}
+	if code := authenticate(request(http.MethodPost, "csrf-1")); code != http.StatusOK {
+		t.Errorf("POST with cookie and CSRF header: status %d, want 200", code)
+	}
+
+	// Without the cookie the header is used, and needs no CSRF token
+	req := httptest.NewRequest(http.MethodPost, "/bookmarks", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	if code := authenticate(req); code != http.StatusOK {
+		t.Errorf("POST with Bearer header: status %d, want 200", code)
+	}
+}
+
+func TestAuthMiddlewareRejectsRevokedOrMissingSession(t *testing.T) {
+	setupTestDB(t)
+	session, token := loggedIn(t)
+	bearer := func() *http.Request {
+		req := httptest.NewRequest(http.MethodGet, "/profile", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		return req
+	}
+
+	if code := authenticate(bearer()); code != http.StatusOK {
+		t.Fatalf("active session: status %d, want 200", code)
+	}
+
+	initializers.DB.Model(&session).Update("revoked_at", time.Now())
+	if code := authenticate(bearer()); code != http.StatusUnauthorized {
+		t.Errorf("revoked session: status %d, want 401", code)
+	}
+
+	initializers.DB.Delete(&session)
+	if code := authenticate(bearer()); code != http.StatusUnauthorized {
+		t.Errorf("deleted session: status %d, want 401", code)
+	}
+}
@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/Algoritma-dan-Pemrograman-ITS/Framework-Programming-GIN-GORM/initializers"
+	"github.com/Algoritma-dan-Pemrograman-ITS/Framework-Programming-GIN-GORM/migrations"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// setupTestDB points initializers.DB at a fresh, fully migrated SQLite database for one test
+func setupTestDB(t *testing.T) {
+	t.Helper()
+	t.Setenv("DB_DRIVER", "sqlite")
+	t.Setenv("DB_URL", filepath.Join(t.TempDir(), "test.db"))
+	initializers.ConnectToDB()
+	initializers.DB = initializers.DB.Session(&gorm.Session{Logger: logger.Default.LogMode(logger.Silent)})
+
+	if _, err := migrations.Up(initializers.DB); err != nil {
+		t.Fatalf("migrating test database: %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := initializers.DB.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+}
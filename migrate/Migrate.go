@@ -1,8 +1,13 @@
 package main
 
 import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
 	"github.com/Algoritma-dan-Pemrograman-ITS/Framework-Programming-GIN-GORM/initializers"
-	"github.com/Algoritma-dan-Pemrograman-ITS/Framework-Programming-GIN-GORM/models"
+	"github.com/Algoritma-dan-Pemrograman-ITS/Framework-Programming-GIN-GORM/migrations"
 )
 
 func init() {
@@ -10,6 +15,66 @@ func init() {
 	initializers.ConnectToDB()
 }
 
+const usage = `Usage:
+  go run migrate/Migrate.go up        apply every pending migration
+  go run migrate/Migrate.go down [N]  roll back the last N migrations (default 1)
+  go run migrate/Migrate.go status    list migrations and whether they are applied`
+
 func main() {
-	initializers.DB.AutoMigrate(&models.Novel{}, &models.User{}, &models.Review{})
+	if len(os.Args) < 2 {
+		fmt.Println(usage)
+		os.Exit(1)
+	}
+
+	switch os.Args[1] {
+	case "up":
+		ran, err := migrations.Up(initializers.DB)
+		for _, m := range ran {
+			fmt.Printf("applied   %04d_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+		if len(ran) == 0 {
+			fmt.Println("Nothing to migrate, schema is up to date")
+		}
+
+	case "down":
+		n := 1
+		if len(os.Args) > 2 {
+			parsed, err := strconv.Atoi(os.Args[2])
+			if err != nil || parsed < 1 {
+				log.Fatalf("Invalid number of migrations: %s", os.Args[2])
+			}
+			n = parsed
+		}
+
+		rolledBack, err := migrations.Down(initializers.DB, n)
+		for _, m := range rolledBack {
+			fmt.Printf("reverted  %04d_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+		if len(rolledBack) == 0 {
+			fmt.Println("Nothing to roll back")
+		}
+
+	case "status":
+		list, err := migrations.StatusList(initializers.DB)
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, s := range list {
+			state := "pending"
+			if s.AppliedAt != nil {
+				state = "applied " + s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d_%-40s %s\n", s.Version, s.Name, state)
+		}
+
+	default:
+		fmt.Println(usage)
+		os.Exit(1)
+	}
 }
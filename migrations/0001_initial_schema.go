@@ -0,0 +1,69 @@
+package migrations
+
+import "gorm.io/gorm"
+
+// Snapshot of the schema the project had while it still used AutoMigrate.
+// These structs are frozen on purpose: later model changes belong in new migrations.
+
+type user0001 struct {
+	ID       uint   `gorm:"primaryKey"`
+	Name     string `gorm:"size:255"`
+	Email    string `gorm:"unique"`
+	Password string `gorm:"size:255"`
+	Role     string `gorm:"default:'user'"`
+}
+
+func (user0001) TableName() string { return "users" }
+
+type novel0001 struct {
+	ID            uint    `gorm:"primaryKey"`
+	Title         string  `gorm:"size:255"`
+	Author        string  `gorm:"size:255"`
+	Rating        float64 `gorm:"size:255"`
+	Language      string  `gorm:"size:255"`
+	YearPublished int     `gorm:"size:255"`
+}
+
+func (novel0001) TableName() string { return "novels" }
+
+type userBookmark0001 struct {
+	UserID  uint      `gorm:"primaryKey"`
+	NovelID uint      `gorm:"primaryKey"`
+	User    user0001  `gorm:"constraint:OnDelete:CASCADE;"`
+	Novel   novel0001 `gorm:"constraint:OnDelete:CASCADE;"`
+}
+
+func (userBookmark0001) TableName() string { return "user_bookmarks" }
+
+type review0001 struct {
+	gorm.Model
+	Rating  float64
+	Comment string
+	UserID  uint
+	NovelID uint
+
+	User  user0001  `gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+	Novel novel0001 `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+}
+
+func (review0001) TableName() string { return "reviews" }
+
+func init() {
+	register(Migration{
+		Version: 1,
+		Name:    "initial_schema",
+		// AutoMigrate on the frozen structs is a no-op for databases created before migrations existed
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&user0001{}, &novel0001{}, &userBookmark0001{}, &review0001{})
+		},
+		// Dropped one by one, dependents first (DropTable with several models may reorder them)
+		Down: func(tx *gorm.DB) error {
+			for _, table := range []interface{}{&review0001{}, &userBookmark0001{}, &novel0001{}, &user0001{}} {
+				if err := tx.Migrator().DropTable(table); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	})
+}
@@ -0,0 +1,115 @@
+package migrations
+
+import (
+	"math"
+	"os"
+	"strconv"
+
+	"gorm.io/gorm"
+)
+
+type novel0003 struct {
+	AverageRating float64 `gorm:"default:0"`
+	ReviewCount   int     `gorm:"default:0"`
+	Rating1Count  int     `gorm:"default:0"`
+	Rating2Count  int     `gorm:"default:0"`
+	Rating3Count  int     `gorm:"default:0"`
+	Rating4Count  int     `gorm:"default:0"`
+	Rating5Count  int     `gorm:"default:0"`
+}
+
+func (novel0003) TableName() string { return "novels" }
+
+var novelRatingColumns0003 = []string{
+	"AverageRating", "ReviewCount",
+	"Rating1Count", "Rating2Count", "Rating3Count", "Rating4Count", "Rating5Count",
+}
+
+// ratingStats0003 is the rating formula as it was when this migration was written:
+// the weighted rating pulls novels with few reviews towards the prior mean
+func ratingStats0003(ratings []float64) map[string]interface{} {
+	var counts [5]int
+	sum := 0.0
+	for _, r := range ratings {
+		sum += r
+		star := int(math.Round(r))
+		if star < 1 {
+			star = 1
+		}
+		if star > 5 {
+			star = 5
+		}
+		counts[star-1]++
+	}
+
+	average := 0.0
+	weighted := 0.0
+	if len(ratings) > 0 {
+		priorMean := floatFromEnv0003("RATING_PRIOR_MEAN", 3.0)
+		priorWeight := floatFromEnv0003("RATING_PRIOR_WEIGHT", 5.0)
+		average = sum / float64(len(ratings))
+		weighted = (priorWeight*priorMean + sum) / (priorWeight + float64(len(ratings)))
+	}
+
+	return map[string]interface{}{
+		"rating":         math.Round(weighted*100) / 100,
+		"average_rating": math.Round(average*100) / 100,
+		"review_count":   len(ratings),
+		"rating1_count":  counts[0],
+		"rating2_count":  counts[1],
+		"rating3_count":  counts[2],
+		"rating4_count":  counts[3],
+		"rating5_count":  counts[4],
+	}
+}
+
+func floatFromEnv0003(key string, fallback float64) float64 {
+	if v, err := strconv.ParseFloat(os.Getenv(key), 64); err == nil && v >= 0 {
+		return v
+	}
+	return fallback
+}
+
+func init() {
+	register(Migration{
+		Version: 3,
+		Name:    "novel_rating_stats",
+		Up: func(tx *gorm.DB) error {
+			for _, column := range novelRatingColumns0003 {
+				if tx.Migrator().HasColumn(&novel0003{}, column) {
+					continue
+				}
+				if err := tx.Migrator().AddColumn(&novel0003{}, column); err != nil {
+					return err
+				}
+			}
+
+			// Ratings used to be typed in by the admin, rebuild them from the reviews
+			var novelIDs []uint
+			if err := tx.Table("novels").Pluck("id", &novelIDs).Error; err != nil {
+				return err
+			}
+			for _, id := range novelIDs {
+				var ratings []float64
+				if err := tx.Table("reviews").Where("novel_id = ? AND deleted_at IS NULL", id).Pluck("rating", &ratings).Error; err != nil {
+					return err
+				}
+				if err := tx.Table("novels").Where("id = ?", id).Updates(ratingStats0003(ratings)).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Down: func(tx *gorm.DB) error {
+			for _, column := range novelRatingColumns0003 {
+				if !tx.Migrator().HasColumn(&novel0003{}, column) {
+					continue
+				}
+				if err := tx.Migrator().DropColumn(&novel0003{}, column); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	})
+}
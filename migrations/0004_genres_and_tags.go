@@ -0,0 +1,54 @@
+package migrations
+
+import "gorm.io/gorm"
+
+type genre0004 struct {
+	ID   uint   `gorm:"primaryKey"`
+	Name string `gorm:"size:100;uniqueIndex"`
+	Slug string `gorm:"size:100;uniqueIndex"`
+}
+
+func (genre0004) TableName() string { return "genres" }
+
+type tag0004 struct {
+	ID   uint   `gorm:"primaryKey"`
+	Name string `gorm:"size:100;uniqueIndex"`
+}
+
+func (tag0004) TableName() string { return "tags" }
+
+type novelGenre0004 struct {
+	NovelID uint      `gorm:"primaryKey"`
+	GenreID uint      `gorm:"primaryKey;index"`
+	Novel   novel0001 `gorm:"constraint:OnDelete:CASCADE;"`
+	Genre   genre0004 `gorm:"constraint:OnDelete:CASCADE;"`
+}
+
+func (novelGenre0004) TableName() string { return "novel_genres" }
+
+type novelTag0004 struct {
+	NovelID uint      `gorm:"primaryKey"`
+	TagID   uint      `gorm:"primaryKey;index"`
+	Novel   novel0001 `gorm:"constraint:OnDelete:CASCADE;"`
+	Tag     tag0004   `gorm:"constraint:OnDelete:CASCADE;"`
+}
+
+func (novelTag0004) TableName() string { return "novel_tags" }
+
+func init() {
+	register(Migration{
+		Version: 4,
+		Name:    "genres_and_tags",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&genre0004{}, &tag0004{}, &novelGenre0004{}, &novelTag0004{})
+		},
+		Down: func(tx *gorm.DB) error {
+			for _, table := range []interface{}{&novelTag0004{}, &novelGenre0004{}, &tag0004{}, &genre0004{}} {
+				if err := tx.Migrator().DropTable(table); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	})
+}
@@ -0,0 +1,36 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type chapter0005 struct {
+	ID          uint   `gorm:"primaryKey"`
+	NovelID     uint   `gorm:"uniqueIndex:idx_chapters_novel_number"`
+	Number      int    `gorm:"uniqueIndex:idx_chapters_novel_number"`
+	Title       string `gorm:"size:255"`
+	Body        string `gorm:"type:text"`
+	WordCount   int
+	PublishedAt *time.Time `gorm:"index"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+
+	Novel novel0001 `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+}
+
+func (chapter0005) TableName() string { return "chapters" }
+
+func init() {
+	register(Migration{
+		Version: 5,
+		Name:    "chapters",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&chapter0005{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&chapter0005{})
+		},
+	})
+}
@@ -0,0 +1,37 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type readingProgress0006 struct {
+	ID          uint   `gorm:"primaryKey"`
+	UserID      uint   `gorm:"uniqueIndex:idx_progress_user_novel"`
+	NovelID     uint   `gorm:"uniqueIndex:idx_progress_user_novel;index"`
+	Status      string `gorm:"size:20;default:'reading'"`
+	LastChapter *int
+	Percentage  float64 `gorm:"default:0"`
+	LastReadAt  *time.Time
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+
+	User  user0001  `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Novel novel0001 `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+}
+
+func (readingProgress0006) TableName() string { return "reading_progress" }
+
+func init() {
+	register(Migration{
+		Version: 6,
+		Name:    "reading_progress",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&readingProgress0006{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&readingProgress0006{})
+		},
+	})
+}
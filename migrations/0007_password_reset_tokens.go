@@ -0,0 +1,33 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type passwordResetToken0007 struct {
+	ID        uint   `gorm:"primaryKey"`
+	UserID    uint   `gorm:"index"`
+	TokenHash string `gorm:"size:64;uniqueIndex"`
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+	CreatedAt time.Time
+
+	User user0001 `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+}
+
+func (passwordResetToken0007) TableName() string { return "password_reset_tokens" }
+
+func init() {
+	register(Migration{
+		Version: 7,
+		Name:    "password_reset_tokens",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&passwordResetToken0007{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&passwordResetToken0007{})
+		},
+	})
+}
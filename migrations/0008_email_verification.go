@@ -0,0 +1,47 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type user0008 struct {
+	EmailVerified   bool `gorm:"default:false"`
+	EmailVerifiedAt *time.Time
+}
+
+func (user0008) TableName() string { return "users" }
+
+func init() {
+	register(Migration{
+		Version: 8,
+		Name:    "email_verification",
+		Up: func(tx *gorm.DB) error {
+			for _, column := range []string{"EmailVerified", "EmailVerifiedAt"} {
+				if tx.Migrator().HasColumn(&user0008{}, column) {
+					continue
+				}
+				if err := tx.Migrator().AddColumn(&user0008{}, column); err != nil {
+					return err
+				}
+			}
+			// Accounts created before verification existed keep working
+			return tx.Table("users").Where("1 = 1").Updates(map[string]interface{}{
+				"email_verified":    true,
+				"email_verified_at": time.Now(),
+			}).Error
+		},
+		Down: func(tx *gorm.DB) error {
+			for _, column := range []string{"EmailVerified", "EmailVerifiedAt"} {
+				if !tx.Migrator().HasColumn(&user0008{}, column) {
+					continue
+				}
+				if err := tx.Migrator().DropColumn(&user0008{}, column); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	})
+}
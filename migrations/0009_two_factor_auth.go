@@ -0,0 +1,61 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type user0009 struct {
+	TOTPSecret   string `gorm:"size:64"`
+	TOTPEnabled  bool   `gorm:"default:false"`
+	TOTPLastStep int64  `gorm:"default:0"`
+}
+
+func (user0009) TableName() string { return "users" }
+
+type recoveryCode0009 struct {
+	ID        uint   `gorm:"primaryKey"`
+	UserID    uint   `gorm:"index"`
+	CodeHash  string `gorm:"size:64;index"`
+	UsedAt    *time.Time
+	CreatedAt time.Time
+
+	User user0001 `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+}
+
+func (recoveryCode0009) TableName() string { return "recovery_codes" }
+
+var userColumns0009 = []string{"TOTPSecret", "TOTPEnabled", "TOTPLastStep"}
+
+func init() {
+	register(Migration{
+		Version: 9,
+		Name:    "two_factor_auth",
+		Up: func(tx *gorm.DB) error {
+			for _, column := range userColumns0009 {
+				if tx.Migrator().HasColumn(&user0009{}, column) {
+					continue
+				}
+				if err := tx.Migrator().AddColumn(&user0009{}, column); err != nil {
+					return err
+				}
+			}
+			return tx.AutoMigrate(&recoveryCode0009{})
+		},
+		Down: func(tx *gorm.DB) error {
+			if err := tx.Migrator().DropTable(&recoveryCode0009{}); err != nil {
+				return err
+			}
+			for _, column := range userColumns0009 {
+				if !tx.Migrator().HasColumn(&user0009{}, column) {
+					continue
+				}
+				if err := tx.Migrator().DropColumn(&user0009{}, column); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	})
+}
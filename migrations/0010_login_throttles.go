@@ -0,0 +1,33 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type loginThrottle0010 struct {
+	ID            uint   `gorm:"primaryKey"`
+	Kind          string `gorm:"size:20;uniqueIndex:idx_throttle_kind_identifier"`
+	Identifier    string `gorm:"size:255;uniqueIndex:idx_throttle_kind_identifier"`
+	Failures      int    `gorm:"default:0"`
+	LastFailureAt time.Time
+	LockedUntil   *time.Time `gorm:"index"`
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+func (loginThrottle0010) TableName() string { return "login_throttles" }
+
+func init() {
+	register(Migration{
+		Version: 10,
+		Name:    "login_throttles",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&loginThrottle0010{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&loginThrottle0010{})
+		},
+	})
+}
@@ -0,0 +1,43 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type user0011 struct {
+	SuspendedAt      *time.Time
+	SuspensionReason string `gorm:"size:255"`
+}
+
+func (user0011) TableName() string { return "users" }
+
+func init() {
+	register(Migration{
+		Version: 11,
+		Name:    "user_suspension",
+		Up: func(tx *gorm.DB) error {
+			for _, column := range []string{"SuspendedAt", "SuspensionReason"} {
+				if tx.Migrator().HasColumn(&user0011{}, column) {
+					continue
+				}
+				if err := tx.Migrator().AddColumn(&user0011{}, column); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Down: func(tx *gorm.DB) error {
+			for _, column := range []string{"SuspendedAt", "SuspensionReason"} {
+				if !tx.Migrator().HasColumn(&user0011{}, column) {
+					continue
+				}
+				if err := tx.Migrator().DropColumn(&user0011{}, column); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	})
+}
@@ -0,0 +1,39 @@
+package migrations
+
+import "gorm.io/gorm"
+
+type user0012 struct {
+	Bio       string `gorm:"type:text"`
+	AvatarURL string `gorm:"size:512"`
+}
+
+func (user0012) TableName() string { return "users" }
+
+func init() {
+	register(Migration{
+		Version: 12,
+		Name:    "user_profile",
+		Up: func(tx *gorm.DB) error {
+			for _, column := range []string{"Bio", "AvatarURL"} {
+				if tx.Migrator().HasColumn(&user0012{}, column) {
+					continue
+				}
+				if err := tx.Migrator().AddColumn(&user0012{}, column); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Down: func(tx *gorm.DB) error {
+			for _, column := range []string{"Bio", "AvatarURL"} {
+				if !tx.Migrator().HasColumn(&user0012{}, column) {
+					continue
+				}
+				if err := tx.Migrator().DropColumn(&user0012{}, column); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	})
+}
@@ -0,0 +1,37 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type apiKey0013 struct {
+	ID         uint   `gorm:"primaryKey"`
+	UserID     uint   `gorm:"index"`
+	Name       string `gorm:"size:100"`
+	Prefix     string `gorm:"size:16"`
+	KeyHash    string `gorm:"size:64;uniqueIndex"`
+	Scopes     string `gorm:"size:255"`
+	ExpiresAt  *time.Time
+	LastUsedAt *time.Time
+	RevokedAt  *time.Time
+	CreatedAt  time.Time
+
+	User user0001 `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+}
+
+func (apiKey0013) TableName() string { return "api_keys" }
+
+func init() {
+	register(Migration{
+		Version: 13,
+		Name:    "api_keys",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&apiKey0013{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&apiKey0013{})
+		},
+	})
+}
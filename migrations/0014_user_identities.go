@@ -0,0 +1,33 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type userIdentity0014 struct {
+	ID        uint   `gorm:"primaryKey"`
+	UserID    uint   `gorm:"index"`
+	Provider  string `gorm:"size:50;uniqueIndex:idx_identity_provider_subject"`
+	Subject   string `gorm:"size:255;uniqueIndex:idx_identity_provider_subject"`
+	Email     string `gorm:"size:255"`
+	CreatedAt time.Time
+
+	User user0001 `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+}
+
+func (userIdentity0014) TableName() string { return "user_identities" }
+
+func init() {
+	register(Migration{
+		Version: 14,
+		Name:    "user_identities",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&userIdentity0014{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&userIdentity0014{})
+		},
+	})
+}
@@ -0,0 +1,41 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type session0015 struct {
+	ID         uint   `gorm:"primaryKey"`
+	UserID     uint   `gorm:"index"`
+	FamilyID   string `gorm:"size:64;uniqueIndex"`
+	UserAgent  string `gorm:"size:512"`
+	IP         string `gorm:"size:64"`
+	CreatedAt  time.Time
+	LastSeenAt time.Time
+	RevokedAt  *time.Time
+
+	User user0001 `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+}
+
+func (session0015) TableName() string { return "sessions" }
+
+func init() {
+	register(Migration{
+		Version: 15,
+		Name:    "sessions",
+		Up: func(tx *gorm.DB) error {
+			if err := tx.AutoMigrate(&session0015{}); err != nil {
+				return err
+			}
+			// One session per existing refresh token family, device details are unknown
+			return tx.Exec(`INSERT INTO sessions (user_id, family_id, user_agent, ip, created_at, last_seen_at, revoked_at)
+				SELECT user_id, family_id, '', '', MIN(created_at), MAX(created_at), MAX(revoked_at)
+				FROM refresh_tokens GROUP BY user_id, family_id`).Error
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&session0015{})
+		},
+	})
+}
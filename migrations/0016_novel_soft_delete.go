@@ -0,0 +1,41 @@
+package migrations
+
+import (
+	"gorm.io/gorm"
+)
+
+type novel0016 struct {
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+}
+
+func (novel0016) TableName() string { return "novels" }
+
+func init() {
+	register(Migration{
+		Version: 16,
+		Name:    "novel_soft_delete",
+		Up: func(tx *gorm.DB) error {
+			if !tx.Migrator().HasColumn(&novel0016{}, "DeletedAt") {
+				if err := tx.Migrator().AddColumn(&novel0016{}, "DeletedAt"); err != nil {
+					return err
+				}
+			}
+			if !tx.Migrator().HasIndex(&novel0016{}, "DeletedAt") {
+				return tx.Migrator().CreateIndex(&novel0016{}, "DeletedAt")
+			}
+			return nil
+		},
+		// Novels still in the trash become visible again
+		Down: func(tx *gorm.DB) error {
+			if tx.Migrator().HasIndex(&novel0016{}, "DeletedAt") {
+				if err := tx.Migrator().DropIndex(&novel0016{}, "DeletedAt"); err != nil {
+					return err
+				}
+			}
+			if tx.Migrator().HasColumn(&novel0016{}, "DeletedAt") {
+				return tx.Migrator().DropColumn(&novel0016{}, "DeletedAt")
+			}
+			return nil
+		},
+	})
+}
@@ -0,0 +1,35 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type novelRevision0017 struct {
+	ID        uint   `gorm:"primaryKey"`
+	NovelID   uint   `gorm:"index"`
+	UserID    *uint  `gorm:"index"`
+	Action    string `gorm:"size:20"`
+	RevertOf  *uint
+	Changes   string `gorm:"type:text"`
+	CreatedAt time.Time
+
+	Novel novel0001 `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	User  *user0001 `gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+}
+
+func (novelRevision0017) TableName() string { return "novel_revisions" }
+
+func init() {
+	register(Migration{
+		Version: 17,
+		Name:    "novel_revisions",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&novelRevision0017{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&novelRevision0017{})
+		},
+	})
+}
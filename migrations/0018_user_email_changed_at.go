@@ -0,0 +1,32 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type user0018 struct {
+	EmailChangedAt *time.Time
+}
+
+func (user0018) TableName() string { return "users" }
+
+func init() {
+	register(Migration{
+		Version: 18,
+		Name:    "user_email_changed_at",
+		Up: func(tx *gorm.DB) error {
+			if tx.Migrator().HasColumn(&user0018{}, "EmailChangedAt") {
+				return nil
+			}
+			return tx.Migrator().AddColumn(&user0018{}, "EmailChangedAt")
+		},
+		Down: func(tx *gorm.DB) error {
+			if !tx.Migrator().HasColumn(&user0018{}, "EmailChangedAt") {
+				return nil
+			}
+			return tx.Migrator().DropColumn(&user0018{}, "EmailChangedAt")
+		},
+	})
+}
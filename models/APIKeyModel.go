@@ -0,0 +1,66 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// API key scopes besides the permission names; a key without scopes can do everything its owner can.
+// Every request needs the method scope, so a scoped key lists read or write and, for staff routes,
+// the permission names it may use on top.
+const (
+	ScopeRead  = "read"  // GET and HEAD requests
+	ScopeWrite = "write" // every other method
+)
+
+// APIKey is a long-lived personal credential sent in the X-API-Key header.
+// Only the sha256 of the key is stored; Prefix is kept so users can tell their keys apart.
+type APIKey struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	UserID     uint       `gorm:"index" json:"user_id"`
+	Name       string     `gorm:"size:100" json:"name"`
+	Prefix     string     `gorm:"size:16" json:"prefix"`
+	KeyHash    string     `gorm:"size:64;uniqueIndex" json:"-"`
+	Scopes     string     `gorm:"size:255" json:"-"` // space separated, see ScopeList
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+
+	User User `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"-"`
+}
+
+// ScopeList returns the scopes of the key, empty when it is unrestricted
+func (k APIKey) ScopeList() []string {
+	return strings.Fields(k.Scopes)
+}
+
+// Allows reports whether the key grants scope. Unrestricted keys allow everything,
+// and "write" implies "read".
+func (k APIKey) Allows(scope string) bool {
+	scopes := k.ScopeList()
+	if len(scopes) == 0 {
+		return true
+	}
+	for _, s := range scopes {
+		if s == scope || (scope == ScopeRead && s == ScopeWrite) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsValidScope reports whether scope is "read", "write" or a permission name
+func IsValidScope(scope string) bool {
+	if scope == ScopeRead || scope == ScopeWrite {
+		return true
+	}
+	for _, perms := range RolePermissions {
+		for _, p := range perms {
+			if p == scope {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,26 @@
+package models
+
+import "testing"
+
+func TestAPIKeyAllows(t *testing.T) {
+	tests := []struct {
+		scopes string
+		scope  string
+		want   bool
+	}{
+		{"", ScopeWrite, true},
+		{"", PermReviewModerate, true},
+		{"read", ScopeRead, true},
+		{"read", ScopeWrite, false},
+		{"write", ScopeRead, true},
+		{"write", ScopeWrite, true},
+		{"write", PermReviewModerate, false},
+		{"write review:moderate", PermReviewModerate, true},
+		{"write review:moderate", PermUserManage, false},
+	}
+	for _, tt := range tests {
+		if got := (APIKey{Scopes: tt.scopes}).Allows(tt.scope); got != tt.want {
+			t.Errorf("key %q Allows(%q) = %v, want %v", tt.scopes, tt.scope, got, tt.want)
+		}
+	}
+}
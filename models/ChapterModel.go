@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// Chapter belongs to a novel; Number is the reading order inside the novel.
+// A chapter with PublishedAt nil (draft) or in the future is hidden from readers.
+type Chapter struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	NovelID     uint       `gorm:"uniqueIndex:idx_chapters_novel_number" json:"novel_id"`
+	Number      int        `gorm:"uniqueIndex:idx_chapters_novel_number" json:"number"`
+	Title       string     `gorm:"size:255" json:"title"`
+	Body        string     `gorm:"type:text" json:"body,omitempty"`
+	WordCount   int        `json:"word_count"`
+	PublishedAt *time.Time `gorm:"index" json:"published_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+
+	Novel Novel `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"-"`
+}
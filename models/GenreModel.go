@@ -0,0 +1,18 @@
+package models
+
+// Genre is the curated list managed by admins (fantasy, xianxia, ...)
+type Genre struct {
+	ID   uint   `gorm:"primaryKey" json:"id"`
+	Name string `gorm:"size:100;uniqueIndex" json:"name"`
+	Slug string `gorm:"size:100;uniqueIndex" json:"slug"`
+
+	Novels []*Novel `gorm:"many2many:novel_genres;" json:"-"`
+}
+
+// Tag is free-form, created on the fly when a novel is tagged with a new name
+type Tag struct {
+	ID   uint   `gorm:"primaryKey" json:"id"`
+	Name string `gorm:"size:100;uniqueIndex" json:"name"`
+
+	Novels []*Novel `gorm:"many2many:novel_tags;" json:"-"`
+}
@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// Kinds of LoginThrottle rows
+const (
+	ThrottleAccount = "account"
+	ThrottleIP      = "ip"
+)
+
+// LoginThrottle counts recent failed logins for one account (email) or one client IP.
+// Kept in the database so the counters survive restarts and are shared by every API instance.
+type LoginThrottle struct {
+	ID            uint       `gorm:"primaryKey" json:"id"`
+	Kind          string     `gorm:"size:20;uniqueIndex:idx_throttle_kind_identifier" json:"kind"`
+	Identifier    string     `gorm:"size:255;uniqueIndex:idx_throttle_kind_identifier" json:"identifier"`
+	Failures      int        `gorm:"default:0" json:"failures"`
+	LastFailureAt time.Time  `json:"last_failure_at"`
+	LockedUntil   *time.Time `gorm:"index" json:"locked_until"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
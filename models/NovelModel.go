@@ -1,13 +1,122 @@
 package models
 
+import (
+	"math"
+	"os"
+	"strconv"
+
+	"gorm.io/gorm"
+)
+
+// Defaults for the weighted rating, can be overridden with RATING_PRIOR_MEAN / RATING_PRIOR_WEIGHT
+const (
+	DefaultRatingPriorMean   = 3.0
+	DefaultRatingPriorWeight = 5.0
+)
+
 type Novel struct {
 	ID            uint    `gorm:"primaryKey" json:"id"`
 	Title         string  `gorm:"size:255" json:"title"`
 	Author        string  `gorm:"size:255" json:"author"`
-	Rating        float64 `gorm:"size:255" json:"rating"`
+	Rating        float64 `gorm:"size:255" json:"rating"` // weighted (bayesian) average, derived from reviews
 	Language      string  `gorm:"size:255" json:"language"`
 	YearPublished int     `gorm:"size:255" json:"year_published"`
+
+	// Derived from reviews, see RecalculateNovelRating
+	AverageRating float64 `gorm:"default:0" json:"average_rating"`
+	ReviewCount   int     `gorm:"default:0" json:"review_count"`
+	Rating1Count  int     `gorm:"default:0" json:"-"`
+	Rating2Count  int     `gorm:"default:0" json:"-"`
+	Rating3Count  int     `gorm:"default:0" json:"-"`
+	Rating4Count  int     `gorm:"default:0" json:"-"`
+	Rating5Count  int     `gorm:"default:0" json:"-"`
+
+	RatingHistogram map[string]int `gorm:"-" json:"rating_histogram"`
+
+	// Set when the novel is in the trash, see controllers.PurgeTrash
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+
 	// add this line to establish relationship with User model
 
 	BookmarkedBy []*User `gorm:"many2many:user_bookmarks;" json:"-"`
+	Genres       []Genre `gorm:"many2many:novel_genres;" json:"genres"`
+	Tags         []Tag   `gorm:"many2many:novel_tags;" json:"tags"`
+}
+
+// AfterFind fills the histogram from the per-star counters so it is present in every response
+func (n *Novel) AfterFind(tx *gorm.DB) error {
+	n.fillHistogram()
+	return nil
+}
+
+// AfterSave does the same for novels returned right after Create/Update
+func (n *Novel) AfterSave(tx *gorm.DB) error {
+	n.fillHistogram()
+	return nil
+}
+
+func (n *Novel) fillHistogram() {
+	n.RatingHistogram = map[string]int{
+		"1": n.Rating1Count,
+		"2": n.Rating2Count,
+		"3": n.Rating3Count,
+		"4": n.Rating4Count,
+		"5": n.Rating5Count,
+	}
+}
+
+// RecalculateNovelRating rebuilds rating, average, review count and histogram of a novel from its reviews
+func RecalculateNovelRating(tx *gorm.DB, novelID uint) error {
+	var ratings []float64
+	if err := tx.Model(&Review{}).Where("novel_id = ?", novelID).Pluck("rating", &ratings).Error; err != nil {
+		return err
+	}
+
+	return tx.Model(&Novel{}).Where("id = ?", novelID).Updates(RatingStats(ratings)).Error
+}
+
+// RatingStats returns the novels columns derived from a list of review ratings.
+// The weighted rating pulls novels with few reviews towards the prior mean:
+// (priorWeight*priorMean + sum) / (priorWeight + count)
+func RatingStats(ratings []float64) map[string]interface{} {
+	var counts [5]int
+	sum := 0.0
+	for _, r := range ratings {
+		sum += r
+		star := int(math.Round(r))
+		if star < 1 {
+			star = 1
+		}
+		if star > 5 {
+			star = 5
+		}
+		counts[star-1]++
+	}
+
+	average := 0.0
+	weighted := 0.0
+	if len(ratings) > 0 {
+		priorMean := floatFromEnv("RATING_PRIOR_MEAN", DefaultRatingPriorMean)
+		priorWeight := floatFromEnv("RATING_PRIOR_WEIGHT", DefaultRatingPriorWeight)
+		average = sum / float64(len(ratings))
+		weighted = (priorWeight*priorMean + sum) / (priorWeight + float64(len(ratings)))
+	}
+
+	return map[string]interface{}{
+		"rating":         math.Round(weighted*100) / 100,
+		"average_rating": math.Round(average*100) / 100,
+		"review_count":   len(ratings),
+		"rating1_count":  counts[0],
+		"rating2_count":  counts[1],
+		"rating3_count":  counts[2],
+		"rating4_count":  counts[3],
+		"rating5_count":  counts[4],
+	}
+}
+
+func floatFromEnv(key string, fallback float64) float64 {
+	if v, err := strconv.ParseFloat(os.Getenv(key), 64); err == nil && v >= 0 {
+		return v
+	}
+	return fallback
 }
@@ -0,0 +1,46 @@
+package models
+
+import "testing"
+
+func TestRatingStats(t *testing.T) {
+	stats := RatingStats([]float64{5, 5, 4, 1})
+	want := map[string]interface{}{
+		// (5*3 + 15) / (5 + 4) = 3.33
+		"rating":         3.33,
+		"average_rating": 3.75,
+		"review_count":   4,
+		"rating1_count":  1,
+		"rating2_count":  0,
+		"rating3_count":  0,
+		"rating4_count":  1,
+		"rating5_count":  2,
+	}
+	for column, value := range want {
+		if stats[column] != value {
+			t.Errorf("%s: got %v, want %v", column, stats[column], value)
+		}
+	}
+}
+
+func TestRatingStatsWithoutReviews(t *testing.T) {
+	stats := RatingStats(nil)
+	if stats["rating"] != 0.0 || stats["average_rating"] != 0.0 || stats["review_count"] != 0 {
+		t.Errorf("got %v, want zero rating, average and count", stats)
+	}
+}
+
+func TestRatingStatsPrior(t *testing.T) {
+	t.Setenv("RATING_PRIOR_MEAN", "4")
+	t.Setenv("RATING_PRIOR_WEIGHT", "0")
+
+	// Without weight the rating is the plain average; half stars round to the nearest bucket
+	stats := RatingStats([]float64{2.5, 4.4})
+	if stats["rating"] != 3.45 || stats["rating3_count"] != 1 || stats["rating4_count"] != 1 {
+		t.Errorf("got %v", stats)
+	}
+
+	t.Setenv("RATING_PRIOR_WEIGHT", "-1")
+	if stats := RatingStats([]float64{5}); stats["rating"] != 4.17 {
+		t.Errorf("negative weight: got rating %v, want the default weight (4.17)", stats["rating"])
+	}
+}
@@ -0,0 +1,58 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// Revision actions
+const (
+	RevisionCreate = "create"
+	RevisionUpdate = "update"
+	RevisionRevert = "revert"
+)
+
+// FieldChange is the value of one novel field before and after a revision (Old is null on create)
+type FieldChange struct {
+	Old interface{} `json:"old"`
+	New interface{} `json:"new"`
+}
+
+// RevisionChanges maps a field name (title, author, language, year_published, genres, tags) to its change.
+// Stored as a JSON text column.
+type RevisionChanges map[string]FieldChange
+
+func (c RevisionChanges) Value() (driver.Value, error) {
+	data, err := json.Marshal(c)
+	return string(data), err
+}
+
+func (c *RevisionChanges) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*c = nil
+		return nil
+	case string:
+		return json.Unmarshal([]byte(v), c)
+	case []byte:
+		return json.Unmarshal(v, c)
+	}
+	return errors.New("unsupported type for RevisionChanges")
+}
+
+// NovelRevision records one create, update or revert of a novel's metadata and who made it.
+// UserID is nil once the user is deleted.
+type NovelRevision struct {
+	ID        uint            `gorm:"primaryKey" json:"id"`
+	NovelID   uint            `gorm:"index" json:"novel_id"`
+	UserID    *uint           `gorm:"index" json:"user_id"`
+	Action    string          `gorm:"size:20" json:"action"`
+	RevertOf  *uint           `json:"revert_of,omitempty"` // revision restored by a revert
+	Changes   RevisionChanges `gorm:"type:text" json:"changes"`
+	CreatedAt time.Time       `json:"created_at"`
+
+	Novel Novel `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"-"`
+	User  *User `gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL;" json:"-"`
+}
@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// Reading statuses accepted for ReadingProgress.Status
+const (
+	StatusWantToRead = "want_to_read"
+	StatusReading    = "reading"
+	StatusCompleted  = "completed"
+	StatusDropped    = "dropped"
+)
+
+// ReadingProgress is where a user is in a novel, one row per user+novel
+type ReadingProgress struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	UserID      uint       `gorm:"uniqueIndex:idx_progress_user_novel" json:"user_id"`
+	NovelID     uint       `gorm:"uniqueIndex:idx_progress_user_novel;index" json:"novel_id"`
+	Status      string     `gorm:"size:20;default:'reading'" json:"status"`
+	LastChapter *int       `json:"last_chapter"`
+	Percentage  float64    `gorm:"default:0" json:"percentage"`
+	LastReadAt  *time.Time `json:"last_read_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+
+	User  User   `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"-"`
+	Novel *Novel `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"novel,omitempty"`
+}
+
+func (ReadingProgress) TableName() string {
+	return "reading_progress"
+}
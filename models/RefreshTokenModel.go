@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// RefreshToken is one link in a rotation chain. Every login starts a new family;
+// each call to /auth/refresh marks the presented token as used and issues the next one.
+type RefreshToken struct {
+	ID        uint       `gorm:"primaryKey" json:"id"`
+	UserID    uint       `gorm:"index" json:"user_id"`
+	FamilyID  string     `gorm:"size:64;index" json:"family_id"`
+	TokenHash string     `gorm:"size:64;uniqueIndex" json:"-"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at"`
+	RevokedAt *time.Time `json:"revoked_at"`
+	CreatedAt time.Time  `json:"created_at"`
+
+	User User `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"-"`
+}
@@ -0,0 +1,68 @@
+package models
+
+// Roles stored in User.Role
+const (
+	RoleAdmin     = "admin"
+	RoleModerator = "moderator"
+	RoleEditor    = "editor"
+	RoleUser      = "user"
+)
+
+// Permissions checked by middleware.RequirePermission and inside controllers
+const (
+	PermNovelWrite     = "novel:write"     // create and update novels and chapters
+	PermNovelDelete    = "novel:delete"    // remove novels and chapters
+	PermNovelRevert    = "novel:revert"    // revert novels to an earlier revision
+	PermGenreWrite     = "genre:write"     // create, update and delete genres
+	PermReviewModerate = "review:moderate" // edit or remove reviews written by others
+	PermUserRead       = "user:read"       // list and inspect user accounts
+	PermUserManage     = "user:manage"     // assign roles and suspend staff accounts
+	PermUserSuspend    = "user:suspend"    // suspend and unsuspend regular accounts
+	PermUserDelete     = "user:delete"     // delete user accounts
+	PermLockoutManage  = "lockout:manage"  // inspect and clear login lockouts
+	PermCatalogExport  = "catalog:export"  // export novels, reviews and bookmark counts
+)
+
+// RolePermissions maps every role to the permissions it grants. Roles are ordered from most to
+// least privileged; a role that is not listed here grants nothing.
+var RolePermissions = map[string][]string{
+	RoleAdmin: {
+		PermNovelWrite, PermNovelDelete, PermNovelRevert, PermGenreWrite, PermReviewModerate,
+		PermUserRead, PermUserManage, PermUserSuspend, PermUserDelete, PermLockoutManage, PermCatalogExport,
+	},
+	RoleModerator: {PermReviewModerate, PermUserRead, PermUserSuspend, PermLockoutManage},
+	RoleEditor:    {PermNovelWrite, PermGenreWrite},
+	RoleUser:      {},
+}
+
+// Roles lists the known roles from most to least privileged
+var Roles = []string{RoleAdmin, RoleModerator, RoleEditor, RoleUser}
+
+// IsValidRole reports whether role is one of Roles
+func IsValidRole(role string) bool {
+	_, ok := RolePermissions[role]
+	return ok
+}
+
+// RoleHasPermission reports whether role grants permission
+func RoleHasPermission(role, permission string) bool {
+	for _, p := range RolePermissions[role] {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// Can reports whether the user's role grants permission
+func (u User) Can(permission string) bool {
+	return RoleHasPermission(u.Role, permission)
+}
+
+// Permissions returns the permissions granted by the user's role
+func (u User) Permissions() []string {
+	if perms, ok := RolePermissions[u.Role]; ok {
+		return perms
+	}
+	return []string{}
+}
@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// Session is one login on one device. It maps 1:1 to a refresh token family (FamilyID is
+// also the "fid" claim of the access tokens), so revoking it logs that device out.
+type Session struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	UserID     uint       `gorm:"index" json:"-"`
+	FamilyID   string     `gorm:"size:64;uniqueIndex" json:"-"`
+	UserAgent  string     `gorm:"size:512" json:"user_agent"`
+	IP         string     `gorm:"size:64" json:"ip"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastSeenAt time.Time  `json:"last_seen_at"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+
+	User User `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"-"`
+}
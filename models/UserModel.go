@@ -1,5 +1,7 @@
 package models
 
+import "time"
+
 type User struct {
 	ID       uint   `gorm:"primaryKey" json:"id"`
 	Name     string `gorm:"size:255" json:"name"`
@@ -7,7 +9,29 @@ type User struct {
 	Password string `gorm:"size:255" json:"-"`
 	Role     string `gorm:"default:'user'" json:"role"`
 
+	// Public profile, editable with PATCH /profile
+	Bio       string `gorm:"type:text" json:"bio"`
+	AvatarURL string `gorm:"size:512" json:"avatar_url"`
+
+	EmailVerified   bool       `gorm:"default:false" json:"email_verified"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`
+	EmailChangedAt  *time.Time `json:"-"` // email change links requested before this no longer work
+
+	// TOTP two-factor authentication; the secret is set by /2fa/setup and only active once TOTPEnabled
+	TOTPSecret   string `gorm:"size:64" json:"-"`
+	TOTPEnabled  bool   `gorm:"default:false" json:"two_factor_enabled"`
+	TOTPLastStep int64  `gorm:"default:0" json:"-"` // last accepted time step, a code cannot be used twice
+
+	// Set by an admin or moderator; AuthMiddleware rejects suspended users on every request
+	SuspendedAt      *time.Time `json:"suspended_at"`
+	SuspensionReason string     `gorm:"size:255" json:"suspension_reason,omitempty"`
+
 	// add this line to establish relationship with Novel model
 	BookmarkedNovels []*Novel `gorm:"many2many:user_bookmarks;" json:"bookmarked_novels"`
 	Reviews          []Review `json:"reviews,omitempty"`
 }
+
+// IsSuspended reports whether the account is currently suspended
+func (u User) IsSuspended() bool {
+	return u.SuspendedAt != nil
+}
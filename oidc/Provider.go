@@ -0,0 +1,388 @@
+package oidc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/big"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// Provider is one external identity provider, configured with OIDC_<NAME>_* variables.
+// With an ISSUER the endpoints come from its discovery document; plain OAuth2 providers
+// (GitHub style) set AUTH_URL, TOKEN_URL and USERINFO_URL instead.
+type Provider struct {
+	Name         string
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	Scopes       []string
+	TrustEmail   bool // treat the provider's email as verified even without an email_verified claim
+
+	AuthURL     string
+	TokenURL    string
+	UserInfoURL string
+	JWKSURL     string
+
+	mu         sync.Mutex
+	discovered bool
+	keys       map[string]interface{}
+	keysAt     time.Time
+}
+
+// Identity is what the provider tells us about the user
+type Identity struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+var (
+	providers = map[string]*Provider{}
+	names     []string
+	client    = &http.Client{Timeout: 10 * time.Second}
+)
+
+// Init reads OIDC_PROVIDERS (comma separated names) and the OIDC_<NAME>_* settings of each.
+// Discovery happens on first use so a provider being down does not stop the server.
+func Init() {
+	providers = map[string]*Provider{}
+	names = nil
+
+	for _, name := range strings.Split(os.Getenv("OIDC_PROVIDERS"), ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		prefix := "OIDC_" + strings.ToUpper(name) + "_"
+		p := &Provider{
+			Name:         name,
+			Issuer:       strings.TrimRight(os.Getenv(prefix+"ISSUER"), "/"),
+			ClientID:     os.Getenv(prefix + "CLIENT_ID"),
+			ClientSecret: os.Getenv(prefix + "CLIENT_SECRET"),
+			Scopes:       strings.Fields(os.Getenv(prefix + "SCOPES")),
+			TrustEmail:   os.Getenv(prefix+"TRUST_EMAIL") == "true",
+			AuthURL:      os.Getenv(prefix + "AUTH_URL"),
+			TokenURL:     os.Getenv(prefix + "TOKEN_URL"),
+			UserInfoURL:  os.Getenv(prefix + "USERINFO_URL"),
+		}
+		if p.ClientID == "" || (p.Issuer == "" && (p.AuthURL == "" || p.TokenURL == "")) {
+			log.Fatalf("OIDC provider %q needs %sCLIENT_ID and either %sISSUER or %sAUTH_URL/%sTOKEN_URL",
+				name, prefix, prefix, prefix, prefix)
+		}
+		if len(p.Scopes) == 0 {
+			p.Scopes = []string{"openid", "email", "profile"}
+		}
+		providers[name] = p
+		names = append(names, name)
+	}
+}
+
+// Get returns a configured provider by name
+func Get(name string) (*Provider, bool) {
+	p, ok := providers[name]
+	return p, ok
+}
+
+// Names lists the configured providers in OIDC_PROVIDERS order
+func Names() []string {
+	return append([]string{}, names...)
+}
+
+// discover fills the endpoints from <issuer>/.well-known/openid-configuration (once)
+func (p *Provider) discover(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.discovered || p.Issuer == "" {
+		return nil
+	}
+
+	var doc struct {
+		Issuer                string `json:"issuer"`
+		AuthorizationEndpoint string `json:"authorization_endpoint"`
+		TokenEndpoint         string `json:"token_endpoint"`
+		UserInfoEndpoint      string `json:"userinfo_endpoint"`
+		JWKSURI               string `json:"jwks_uri"`
+	}
+	if err := getJSON(ctx, p.Issuer+"/.well-known/openid-configuration", "", &doc); err != nil {
+		return fmt.Errorf("oidc discovery for %s: %w", p.Name, err)
+	}
+	if strings.TrimRight(doc.Issuer, "/") != p.Issuer {
+		return fmt.Errorf("oidc discovery for %s: issuer mismatch %q", p.Name, doc.Issuer)
+	}
+
+	// Explicit settings win over discovery
+	if p.AuthURL == "" {
+		p.AuthURL = doc.AuthorizationEndpoint
+	}
+	if p.TokenURL == "" {
+		p.TokenURL = doc.TokenEndpoint
+	}
+	if p.UserInfoURL == "" {
+		p.UserInfoURL = doc.UserInfoEndpoint
+	}
+	p.JWKSURL = doc.JWKSURI
+	p.discovered = true
+	return nil
+}
+
+// AuthCodeURL builds the authorization request (authorization code flow with PKCE S256)
+func (p *Provider) AuthCodeURL(ctx context.Context, redirectURI, state, nonce, verifier string) (string, error) {
+	if err := p.discover(ctx); err != nil {
+		return "", err
+	}
+
+	challenge := sha256.Sum256([]byte(verifier))
+	q := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {p.ClientID},
+		"redirect_uri":          {redirectURI},
+		"scope":                 {strings.Join(p.Scopes, " ")},
+		"state":                 {state},
+		"nonce":                 {nonce},
+		"code_challenge":        {base64.RawURLEncoding.EncodeToString(challenge[:])},
+		"code_challenge_method": {"S256"},
+	}
+
+	sep := "?"
+	if strings.Contains(p.AuthURL, "?") {
+		sep = "&"
+	}
+	return p.AuthURL + sep + q.Encode(), nil
+}
+
+// Exchange trades the authorization code for tokens and returns the user's identity.
+// The ID token is verified when the provider returns one; otherwise the userinfo endpoint is used.
+func (p *Provider) Exchange(ctx context.Context, redirectURI, code, verifier, nonce string) (*Identity, error) {
+	if err := p.discover(ctx); err != nil {
+		return nil, err
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {redirectURI},
+		"client_id":     {p.ClientID},
+		"client_secret": {p.ClientSecret},
+		"code_verifier": {verifier},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	var tokens struct {
+		AccessToken string `json:"access_token"`
+		IDToken     string `json:"id_token"`
+		Error       string `json:"error"`
+	}
+	if err := doJSON(req, &tokens); err != nil {
+		return nil, fmt.Errorf("token exchange: %w", err)
+	}
+	if tokens.Error != "" {
+		return nil, fmt.Errorf("token exchange: %s", tokens.Error)
+	}
+
+	var claims map[string]interface{}
+	if tokens.IDToken != "" {
+		if claims, err = p.verifyIDToken(ctx, tokens.IDToken, nonce); err != nil {
+			return nil, err
+		}
+	} else if p.UserInfoURL != "" && tokens.AccessToken != "" {
+		if err := getJSON(ctx, p.UserInfoURL, tokens.AccessToken, &claims); err != nil {
+			return nil, fmt.Errorf("userinfo: %w", err)
+		}
+	} else {
+		return nil, errors.New("provider returned neither an id_token nor a userinfo endpoint")
+	}
+
+	return p.identityFromClaims(claims)
+}
+
+func (p *Provider) identityFromClaims(claims map[string]interface{}) (*Identity, error) {
+	identity := &Identity{}
+	// "sub" for OIDC, numeric "id" for GitHub style userinfo
+	switch sub := claims["sub"].(type) {
+	case string:
+		identity.Subject = sub
+	}
+	if identity.Subject == "" {
+		if id, ok := claims["id"].(float64); ok {
+			identity.Subject = fmt.Sprintf("%.0f", id)
+		}
+	}
+	if identity.Subject == "" {
+		return nil, errors.New("provider did not return a subject")
+	}
+
+	identity.Email, _ = claims["email"].(string)
+	switch v := claims["email_verified"].(type) {
+	case bool:
+		identity.EmailVerified = v
+	case string: // some providers send "true"
+		identity.EmailVerified = v == "true"
+	}
+	if p.TrustEmail && identity.Email != "" {
+		identity.EmailVerified = true
+	}
+
+	identity.Name, _ = claims["name"].(string)
+	if identity.Name == "" {
+		identity.Name, _ = claims["login"].(string)
+	}
+	return identity, nil
+}
+
+// verifyIDToken checks the signature against the provider's JWKS, then iss, aud, exp and nonce
+func (p *Provider) verifyIDToken(ctx context.Context, idToken, nonce string) (map[string]interface{}, error) {
+	if p.JWKSURL == "" {
+		return nil, errors.New("id_token received but the provider has no jwks_uri")
+	}
+
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(idToken, claims, func(t *jwt.Token) (interface{}, error) {
+		switch t.Method.(type) {
+		case *jwt.SigningMethodRSA, *jwt.SigningMethodECDSA, *jwt.SigningMethodRSAPSS:
+		default:
+			return nil, fmt.Errorf("unexpected id_token algorithm %v", t.Header["alg"])
+		}
+		kid, _ := t.Header["kid"].(string)
+		return p.key(ctx, kid)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid id_token: %w", err)
+	}
+
+	if p.Issuer != "" && !claims.VerifyIssuer(p.Issuer, true) {
+		return nil, errors.New("invalid id_token: wrong issuer")
+	}
+	if !claims.VerifyAudience(p.ClientID, true) {
+		return nil, errors.New("invalid id_token: wrong audience")
+	}
+	if got, _ := claims["nonce"].(string); got != nonce {
+		return nil, errors.New("invalid id_token: nonce mismatch")
+	}
+	return claims, nil
+}
+
+// key returns the verification key for kid, refetching the JWKS (at most once a minute) when it is unknown
+func (p *Provider) key(ctx context.Context, kid string) (interface{}, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if k, ok := p.cachedKey(kid); ok {
+		return k, nil
+	}
+	if time.Since(p.keysAt) < time.Minute && p.keys != nil {
+		return nil, fmt.Errorf("unknown key id %q", kid)
+	}
+
+	var set struct {
+		Keys []struct {
+			Kid string `json:"kid"`
+			Kty string `json:"kty"`
+			Crv string `json:"crv"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+			X   string `json:"x"`
+			Y   string `json:"y"`
+		} `json:"keys"`
+	}
+	if err := getJSON(ctx, p.JWKSURL, "", &set); err != nil {
+		return nil, fmt.Errorf("fetching jwks: %w", err)
+	}
+
+	keys := map[string]interface{}{}
+	for _, k := range set.Keys {
+		switch k.Kty {
+		case "RSA":
+			n, errN := base64.RawURLEncoding.DecodeString(k.N)
+			e, errE := base64.RawURLEncoding.DecodeString(k.E)
+			if errN != nil || errE != nil {
+				continue
+			}
+			keys[k.Kid] = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+		case "EC":
+			var curve elliptic.Curve
+			switch k.Crv {
+			case "P-256":
+				curve = elliptic.P256()
+			case "P-384":
+				curve = elliptic.P384()
+			default:
+				continue
+			}
+			x, errX := base64.RawURLEncoding.DecodeString(k.X)
+			y, errY := base64.RawURLEncoding.DecodeString(k.Y)
+			if errX != nil || errY != nil {
+				continue
+			}
+			keys[k.Kid] = &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		}
+	}
+	p.keys, p.keysAt = keys, time.Now()
+
+	if k, ok := p.cachedKey(kid); ok {
+		return k, nil
+	}
+	return nil, fmt.Errorf("unknown key id %q", kid)
+}
+
+// cachedKey looks kid up in the last fetched JWKS. A provider with a single key may omit kid from its tokens.
+func (p *Provider) cachedKey(kid string) (interface{}, bool) {
+	if kid == "" && len(p.keys) == 1 {
+		for _, k := range p.keys {
+			return k, true
+		}
+	}
+	k, ok := p.keys[kid]
+	return k, ok
+}
+
+func getJSON(ctx context.Context, endpoint, bearer string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if bearer != "" {
+		req.Header.Set("Authorization", "Bearer "+bearer)
+	}
+	return doJSON(req, out)
+}
+
+func doJSON(req *http.Request, out interface{}) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	// Token endpoints answer errors with 400 and a JSON body, let the caller read "error"
+	if resp.StatusCode >= 300 && resp.StatusCode != http.StatusBadRequest {
+		return fmt.Errorf("%s returned %s", req.URL.Host, resp.Status)
+	}
+	return json.Unmarshal(body, out)
+}
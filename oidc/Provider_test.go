@@ -0,0 +1,132 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// testProvider serves a JWKS with one RSA key (kid "k1") and returns a provider using it
+func testProvider(t *testing.T) (*Provider, *rsa.PrivateKey) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{"keys": []map[string]string{{
+			"kid": "k1",
+			"kty": "RSA",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	}))
+	t.Cleanup(server.Close)
+
+	return &Provider{Name: "test", Issuer: "https://issuer.example", ClientID: "client-1", JWKSURL: server.URL}, key
+}
+
+func validClaims() jwt.MapClaims {
+	return jwt.MapClaims{
+		"iss":   "https://issuer.example",
+		"aud":   "client-1",
+		"sub":   "user-1",
+		"nonce": "nonce-1",
+		"exp":   time.Now().Add(time.Hour).Unix(),
+	}
+}
+
+func signRS256(t *testing.T, key *rsa.PrivateKey, kid string, claims jwt.MapClaims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+func TestVerifyIDToken(t *testing.T) {
+	provider, key := testProvider(t)
+
+	claims, err := provider.verifyIDToken(context.Background(), signRS256(t, key, "k1", validClaims()), "nonce-1")
+	if err != nil {
+		t.Fatalf("valid id_token rejected: %v", err)
+	}
+	if claims["sub"] != "user-1" {
+		t.Errorf("got sub %v, want user-1", claims["sub"])
+	}
+}
+
+func TestVerifyIDTokenWithoutKidUsesCachedSingleKey(t *testing.T) {
+	provider, key := testProvider(t)
+
+	// The second token is verified inside the cache window, without a refetch
+	for i := 0; i < 2; i++ {
+		if _, err := provider.verifyIDToken(context.Background(), signRS256(t, key, "", validClaims()), "nonce-1"); err != nil {
+			t.Fatalf("id_token %d without kid rejected: %v", i+1, err)
+		}
+	}
+}
+
+func TestVerifyIDTokenRejects(t *testing.T) {
+	provider, key := testProvider(t)
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	with := func(name string, value interface{}) jwt.MapClaims {
+		claims := validClaims()
+		claims[name] = value
+		return claims
+	}
+	hs256, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, validClaims()).SignedString([]byte("client-secret"))
+
+	tests := map[string]string{
+		"wrong issuer":     signRS256(t, key, "k1", with("iss", "https://evil.example")),
+		"wrong audience":   signRS256(t, key, "k1", with("aud", "another-client")),
+		"expired":          signRS256(t, key, "k1", with("exp", time.Now().Add(-time.Minute).Unix())),
+		"nonce mismatch":   signRS256(t, key, "k1", with("nonce", "replayed")),
+		"missing nonce":    signRS256(t, key, "k1", with("nonce", nil)),
+		"unknown key id":   signRS256(t, key, "k2", validClaims()),
+		"wrong signature":  signRS256(t, otherKey, "k1", validClaims()),
+		"symmetric alg":    hs256,
+		"not a jwt at all": "abc.def.ghi",
+	}
+	for name, idToken := range tests {
+		if _, err := provider.verifyIDToken(context.Background(), idToken, "nonce-1"); err == nil {
+			t.Errorf("%s: id_token accepted", name)
+		}
+	}
+}
+
+func TestIdentityFromClaims(t *testing.T) {
+	provider := &Provider{Name: "test"}
+
+	identity, err := provider.identityFromClaims(map[string]interface{}{"sub": "s1", "email": "a@example.com", "email_verified": "true"})
+	if err != nil || identity.Subject != "s1" || !identity.EmailVerified {
+		t.Errorf("got %+v, %v; want subject s1 with a verified email", identity, err)
+	}
+
+	// GitHub style userinfo: numeric id, no email_verified unless the provider is trusted
+	identity, err = provider.identityFromClaims(map[string]interface{}{"id": float64(42), "email": "a@example.com", "login": "alice"})
+	if err != nil || identity.Subject != "42" || identity.EmailVerified || identity.Name != "alice" {
+		t.Errorf("got %+v, %v; want subject 42, unverified email, name alice", identity, err)
+	}
+
+	if _, err := provider.identityFromClaims(map[string]interface{}{"email": "a@example.com"}); err == nil {
+		t.Error("claims without a subject accepted")
+	}
+}
@@ -0,0 +1,114 @@
+// Package search is the full-text index behind GET /search. Backends implement Index and are
+// picked with SEARCH_BACKEND; the database stays the source of truth and the index can always
+// be rebuilt from it.
+package search
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Document is the searchable part of a novel
+type Document struct {
+	ID       uint
+	Title    string
+	Author   string
+	Language string
+	Year     int
+}
+
+// Query is one search request. Language and Year (0 = any) narrow the results,
+// Fuzzy also matches terms with a typo or two.
+type Query struct {
+	Text     string
+	Language string
+	Year     int
+	Fuzzy    bool
+	Offset   int
+	Limit    int
+}
+
+// Hit is one matching document. Highlights holds the matched fields with the
+// matching words wrapped in <mark></mark>, the rest of the text is HTML escaped.
+type Hit struct {
+	ID         uint              `json:"id"`
+	Score      float64           `json:"score"`
+	Highlights map[string]string `json:"highlights"`
+}
+
+// FacetCount is the number of matches for one facet value
+type FacetCount struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+// Result holds one page of hits, the total number of matches and the facets.
+// Each facet ignores its own filter, so picking a language still shows the counts of the others.
+type Result struct {
+	Total  int                     `json:"total"`
+	Hits   []Hit                   `json:"hits"`
+	Facets map[string][]FacetCount `json:"facets"`
+}
+
+// Index is a search backend
+type Index interface {
+	// Index adds or replaces documents
+	Index(docs ...Document) error
+	// Delete removes documents, unknown ids are ignored
+	Delete(ids ...uint) error
+	// Reset replaces the whole content of the index
+	Reset(docs []Document) error
+	Search(q Query) (*Result, error)
+	Count() int
+}
+
+// Factory opens a backend; path is SEARCH_INDEX_PATH
+type Factory func(path string) (Index, error)
+
+var backends = map[string]Factory{
+	"file":   func(path string) (Index, error) { return OpenMemoryIndex(path) },
+	"memory": func(string) (Index, error) { return OpenMemoryIndex("") },
+}
+
+// Register makes another backend available to SEARCH_BACKEND
+func Register(name string, factory Factory) {
+	backends[name] = factory
+}
+
+// Default is the index used by the controllers. Until Init runs it is an empty in-memory index.
+var Default Index = newMemoryIndex()
+
+const defaultIndexPath = "data/search.idx"
+
+// Init opens the backend named by SEARCH_BACKEND (file by default, or memory)
+func Init() error {
+	name := strings.ToLower(os.Getenv("SEARCH_BACKEND"))
+	if name == "" {
+		name = "file"
+	}
+	factory, ok := backends[name]
+	if !ok {
+		names := make([]string, 0, len(backends))
+		for n := range backends {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return fmt.Errorf("unknown SEARCH_BACKEND %q, allowed: %s", name, strings.Join(names, ", "))
+	}
+
+	path := os.Getenv("SEARCH_INDEX_PATH")
+	if path == "" {
+		path = defaultIndexPath
+	}
+
+	index, err := factory(path)
+	if err != nil {
+		return err
+	}
+	Default = index
+	log.Printf("search: %s index with %d documents", name, index.Count())
+	return nil
+}
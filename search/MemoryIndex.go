@@ -0,0 +1,366 @@
+package search
+
+import (
+	"encoding/gob"
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Searched fields and how much a match in each one is worth
+const (
+	fieldTitle = iota
+	fieldAuthor
+	numFields
+)
+
+var fieldBoosts = [numFields]float64{3, 2}
+
+// Weight of a query term matching an index term exactly, as a prefix ("tolk" -> "tolkien") or with typos
+const (
+	exactWeight  = 1.0
+	prefixWeight = 0.7
+	fuzzyWeight  = 0.5
+	phraseBoost  = 1.5
+)
+
+type posting [numFields]int // term frequency per field
+
+// memoryIndex is an inverted index held in memory. With a path every change is also written
+// to that file (documents only, the postings are rebuilt on load), so restarts don't need a rebuild.
+type memoryIndex struct {
+	mu      sync.RWMutex
+	path    string
+	docs    map[uint]Document
+	lengths map[uint][numFields]int // words per field
+	terms   map[string]map[uint]*posting
+}
+
+func newMemoryIndex() *memoryIndex {
+	return &memoryIndex{docs: map[uint]Document{}, lengths: map[uint][numFields]int{}, terms: map[string]map[uint]*posting{}}
+}
+
+// OpenMemoryIndex loads the index stored at path (or starts empty). An empty path keeps it in memory only.
+func OpenMemoryIndex(path string) (Index, error) {
+	index := newMemoryIndex()
+	index.path = path
+	if path == "" {
+		return index, nil
+	}
+
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return index, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var docs []Document
+	if err := gob.NewDecoder(file).Decode(&docs); err != nil {
+		// A broken file is not fatal, the caller notices the empty index and rebuilds it
+		return index, nil
+	}
+	for _, doc := range docs {
+		index.add(doc)
+	}
+	return index, nil
+}
+
+func (m *memoryIndex) Index(docs ...Document) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, doc := range docs {
+		m.remove(doc.ID)
+		m.add(doc)
+	}
+	return m.save()
+}
+
+func (m *memoryIndex) Delete(ids ...uint) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, id := range ids {
+		m.remove(id)
+	}
+	return m.save()
+}
+
+func (m *memoryIndex) Reset(docs []Document) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.docs = map[uint]Document{}
+	m.lengths = map[uint][numFields]int{}
+	m.terms = map[string]map[uint]*posting{}
+	for _, doc := range docs {
+		m.add(doc)
+	}
+	return m.save()
+}
+
+func (m *memoryIndex) Count() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return len(m.docs)
+}
+
+func (m *memoryIndex) add(doc Document) {
+	m.docs[doc.ID] = doc
+	var lengths [numFields]int
+	for field, text := range fieldTexts(doc) {
+		terms := tokenize(text)
+		lengths[field] = len(terms)
+		for _, term := range terms {
+			postings := m.terms[term]
+			if postings == nil {
+				postings = map[uint]*posting{}
+				m.terms[term] = postings
+			}
+			p := postings[doc.ID]
+			if p == nil {
+				p = &posting{}
+				postings[doc.ID] = p
+			}
+			p[field]++
+		}
+	}
+	m.lengths[doc.ID] = lengths
+}
+
+func (m *memoryIndex) remove(id uint) {
+	doc, ok := m.docs[id]
+	if !ok {
+		return
+	}
+	delete(m.docs, id)
+	delete(m.lengths, id)
+	for _, text := range fieldTexts(doc) {
+		for _, term := range tokenize(text) {
+			if postings := m.terms[term]; postings != nil {
+				delete(postings, id)
+				if len(postings) == 0 {
+					delete(m.terms, term)
+				}
+			}
+		}
+	}
+}
+
+// save writes the documents to a temporary file first, so a crash never leaves half an index behind
+func (m *memoryIndex) save() error {
+	if m.path == "" {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(m.path), 0o755); err != nil {
+		return err
+	}
+
+	docs := make([]Document, 0, len(m.docs))
+	for _, doc := range m.docs {
+		docs = append(docs, doc)
+	}
+
+	tmp := m.path + ".tmp"
+	file, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	if err := gob.NewEncoder(file).Encode(docs); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, m.path)
+}
+
+func (m *memoryIndex) Search(q Query) (*Result, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	result := &Result{Hits: []Hit{}, Facets: map[string][]FacetCount{"language": {}, "year": {}}}
+	queryTerms := unique(tokenize(q.Text))
+	if len(queryTerms) == 0 {
+		return result, nil
+	}
+
+	// Every query term has to match (in any field), the scores of the terms add up
+	scores := map[uint]float64{}
+	matched := map[uint]map[string]bool{}
+	for i, queryTerm := range queryTerms {
+		termScores := map[uint]float64{}
+		for term, weight := range m.expand(queryTerm, q.Fuzzy) {
+			postings := m.terms[term]
+			idf := math.Log(1 + float64(len(m.docs))/float64(len(postings)))
+			for id, p := range postings {
+				if i > 0 {
+					if _, ok := scores[id]; !ok {
+						continue
+					}
+				}
+				score := weight * idf * m.fieldScore(id, p)
+				if score > termScores[id] {
+					termScores[id] = score
+				}
+				if matched[id] == nil {
+					matched[id] = map[string]bool{}
+				}
+				matched[id][term] = true
+			}
+		}
+
+		next := map[uint]float64{}
+		for id, score := range termScores {
+			next[id] = scores[id] + score
+		}
+		scores = next
+		if len(scores) == 0 {
+			return result, nil
+		}
+	}
+
+	// Whole query found in order in the title or author, e.g. "lord of the rings"
+	phrase := strings.Join(queryTerms, " ")
+	languageCounts := newFacet()
+	yearCounts := newFacet()
+	var hits []Hit
+	for id, score := range scores {
+		doc := m.docs[id]
+		if len(queryTerms) > 1 {
+			for _, text := range fieldTexts(doc) {
+				if strings.Contains(" "+strings.Join(tokenize(text), " ")+" ", " "+phrase+" ") {
+					score *= phraseBoost
+					break
+				}
+			}
+		}
+
+		languageOK := q.Language == "" || strings.EqualFold(doc.Language, q.Language)
+		yearOK := q.Year == 0 || doc.Year == q.Year
+		if yearOK && doc.Language != "" {
+			languageCounts.add(doc.Language)
+		}
+		if languageOK && doc.Year != 0 {
+			yearCounts.add(strconv.Itoa(doc.Year))
+		}
+		if languageOK && yearOK {
+			hits = append(hits, Hit{ID: id, Score: math.Round(score*1000) / 1000})
+		}
+	}
+
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].ID < hits[j].ID
+	})
+
+	result.Total = len(hits)
+	result.Facets["language"] = languageCounts.list()
+	result.Facets["year"] = yearCounts.list()
+
+	start, end := q.Offset, q.Offset+q.Limit
+	if start > len(hits) {
+		start = len(hits)
+	}
+	if q.Limit <= 0 || end > len(hits) {
+		end = len(hits)
+	}
+	for _, hit := range hits[start:end] {
+		doc := m.docs[hit.ID]
+		hit.Highlights = map[string]string{}
+		if text, ok := highlight(doc.Title, matched[hit.ID]); ok {
+			hit.Highlights["title"] = text
+		}
+		if text, ok := highlight(doc.Author, matched[hit.ID]); ok {
+			hit.Highlights["author"] = text
+		}
+		result.Hits = append(result.Hits, hit)
+	}
+	return result, nil
+}
+
+// expand returns the index terms a query term matches, with the weight of each kind of match
+func (m *memoryIndex) expand(queryTerm string, fuzzy bool) map[string]float64 {
+	expansions := map[string]float64{}
+	maxEdits := allowedEdits(queryTerm)
+	for term := range m.terms {
+		switch {
+		case term == queryTerm:
+			expansions[term] = exactWeight
+		case len(queryTerm) >= 2 && strings.HasPrefix(term, queryTerm):
+			expansions[term] = prefixWeight
+		case fuzzy && maxEdits > 0 && withinEdits(queryTerm, term, maxEdits):
+			expansions[term] = fuzzyWeight
+		}
+	}
+	return expansions
+}
+
+// fieldScore favours matches in short fields, a title of two words matching is worth more than one of ten
+func (m *memoryIndex) fieldScore(id uint, p *posting) float64 {
+	lengths := m.lengths[id]
+	score := 0.0
+	for field, tf := range p {
+		if tf == 0 {
+			continue
+		}
+		score += fieldBoosts[field] * (1 + math.Log(float64(tf))) / math.Sqrt(float64(lengths[field]))
+	}
+	return score
+}
+
+func fieldTexts(doc Document) [numFields]string {
+	return [numFields]string{fieldTitle: doc.Title, fieldAuthor: doc.Author}
+}
+
+func unique(terms []string) []string {
+	seen := map[string]bool{}
+	var out []string
+	for _, term := range terms {
+		if !seen[term] {
+			seen[term] = true
+			out = append(out, term)
+		}
+	}
+	return out
+}
+
+// facet counts values case-insensitively and reports the first spelling it saw
+type facet struct {
+	counts map[string]int
+	labels map[string]string
+}
+
+func newFacet() *facet {
+	return &facet{counts: map[string]int{}, labels: map[string]string{}}
+}
+
+func (f *facet) add(value string) {
+	key := strings.ToLower(value)
+	if _, ok := f.labels[key]; !ok {
+		f.labels[key] = value
+	}
+	f.counts[key]++
+}
+
+func (f *facet) list() []FacetCount {
+	list := make([]FacetCount, 0, len(f.counts))
+	for key, count := range f.counts {
+		list = append(list, FacetCount{Value: f.labels[key], Count: count})
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Count != list[j].Count {
+			return list[i].Count > list[j].Count
+		}
+		return list[i].Value < list[j].Value
+	})
+	return list
+}
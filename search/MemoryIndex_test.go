@@ -0,0 +1,157 @@
+package search
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func newTestIndex(t *testing.T, docs ...Document) Index {
+	t.Helper()
+	index, err := OpenMemoryIndex("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := index.Index(docs...); err != nil {
+		t.Fatal(err)
+	}
+	return index
+}
+
+func hitIDs(result *Result) []uint {
+	ids := []uint{}
+	for _, hit := range result.Hits {
+		ids = append(ids, hit.ID)
+	}
+	return ids
+}
+
+func TestSearchRanksTitleAboveAuthor(t *testing.T) {
+	index := newTestIndex(t,
+		Document{ID: 1, Title: "The Hobbit", Author: "J.R.R. Tolkien"},
+		Document{ID: 2, Title: "Tolkien: A Biography", Author: "Humphrey Carpenter"},
+		Document{ID: 3, Title: "Dune", Author: "Frank Herbert"},
+	)
+
+	result, err := index.Search(Query{Text: "tolkien"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := hitIDs(result); !reflect.DeepEqual(got, []uint{2, 1}) || result.Total != 2 {
+		t.Fatalf("got hits %v (total %d), want the title match 2 before the author match 1", got, result.Total)
+	}
+	if result.Hits[0].Score <= result.Hits[1].Score {
+		t.Errorf("title match scored %v, author match %v", result.Hits[0].Score, result.Hits[1].Score)
+	}
+
+	// Every query term has to match somewhere
+	result, _ = index.Search(Query{Text: "hobbit herbert"})
+	if result.Total != 0 {
+		t.Errorf("terms matching different novels: got hits %v, want none", hitIDs(result))
+	}
+}
+
+func TestSearchPrefixAndFuzzyMatches(t *testing.T) {
+	index := newTestIndex(t,
+		Document{ID: 1, Title: "The Hobbit", Author: "J.R.R. Tolkien"},
+		Document{ID: 2, Title: "Dune", Author: "Frank Herbert"},
+	)
+
+	tests := []struct {
+		text  string
+		fuzzy bool
+		want  []uint
+	}{
+		{"tolk", false, []uint{1}},
+		{"hobit", false, []uint{}},
+		{"hobit", true, []uint{1}},
+		{"tolkein", true, []uint{1}},
+		// Words of three letters or less need an exact or prefix match
+		{"dun", true, []uint{2}},
+		{"dine", false, []uint{}},
+		{"fun", true, []uint{}},
+	}
+	for _, tt := range tests {
+		result, err := index.Search(Query{Text: tt.text, Fuzzy: tt.fuzzy})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := hitIDs(result); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q (fuzzy %v): got hits %v, want %v", tt.text, tt.fuzzy, got, tt.want)
+		}
+	}
+
+	// An exact match beats a typo of the same word
+	index.Index(Document{ID: 3, Title: "Hobit", Author: "Someone"})
+	result, _ := index.Search(Query{Text: "hobit", Fuzzy: true})
+	if got := hitIDs(result); !reflect.DeepEqual(got, []uint{3, 1}) {
+		t.Errorf("got hits %v, want the exact match 3 first", got)
+	}
+}
+
+func TestSearchFacetsIgnoreTheirOwnFilter(t *testing.T) {
+	index := newTestIndex(t,
+		Document{ID: 1, Title: "The Dragon Reborn", Author: "Robert Jordan", Language: "english", Year: 1991},
+		Document{ID: 2, Title: "Dragonflight", Author: "Anne McCaffrey", Language: "English", Year: 1968},
+		Document{ID: 3, Title: "Le Dragon", Author: "Jean Dupont", Language: "french", Year: 1991},
+	)
+
+	result, err := index.Search(Query{Text: "dragon", Language: "french"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := hitIDs(result); !reflect.DeepEqual(got, []uint{3}) || result.Total != 1 {
+		t.Errorf("language filter: got hits %v (total %d), want [3]", got, result.Total)
+	}
+	wantLanguages := []FacetCount{{Value: "english", Count: 2}, {Value: "french", Count: 1}}
+	if !reflect.DeepEqual(result.Facets["language"], wantLanguages) {
+		t.Errorf("language facet %v, want %v", result.Facets["language"], wantLanguages)
+	}
+	wantYears := []FacetCount{{Value: "1991", Count: 1}}
+	if !reflect.DeepEqual(result.Facets["year"], wantYears) {
+		t.Errorf("year facet %v, want %v", result.Facets["year"], wantYears)
+	}
+
+	result, _ = index.Search(Query{Text: "dragon", Year: 1991})
+	if got := hitIDs(result); len(got) != 2 {
+		t.Errorf("year filter: got hits %v, want 1 and 3", got)
+	}
+	wantYears = []FacetCount{{Value: "1991", Count: 2}, {Value: "1968", Count: 1}}
+	if !reflect.DeepEqual(result.Facets["year"], wantYears) {
+		t.Errorf("year facet %v, want %v", result.Facets["year"], wantYears)
+	}
+}
+
+func TestSearchHighlightsEscapeHTML(t *testing.T) {
+	index := newTestIndex(t, Document{ID: 1, Title: "Tom & Jerry <3", Author: "Hanna Barbera"})
+
+	result, _ := index.Search(Query{Text: "jerry"})
+	if len(result.Hits) != 1 {
+		t.Fatalf("got hits %v, want [1]", hitIDs(result))
+	}
+	want := map[string]string{"title": "Tom &amp; <mark>Jerry</mark> &lt;3"}
+	if !reflect.DeepEqual(result.Hits[0].Highlights, want) {
+		t.Errorf("highlights %v, want %v", result.Hits[0].Highlights, want)
+	}
+}
+
+func TestMemoryIndexPersistsDocuments(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "index.gob")
+	index, err := OpenMemoryIndex(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	index.Index(Document{ID: 1, Title: "The Hobbit"}, Document{ID: 2, Title: "Dune"})
+	index.Delete(2)
+
+	reopened, err := OpenMemoryIndex(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reopened.Count() != 1 {
+		t.Fatalf("reopened index has %d documents, want 1", reopened.Count())
+	}
+	if result, _ := reopened.Search(Query{Text: "hobbit"}); result.Total != 1 {
+		t.Error("reopened index does not find the stored document")
+	}
+}
@@ -0,0 +1,108 @@
+package search
+
+import (
+	"html"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type token struct {
+	term       string
+	start, end int // byte offsets in the original text
+}
+
+// tokens splits text into lower case words of letters and digits
+func tokens(text string) []token {
+	var out []token
+	start := -1
+	for i, r := range text {
+		isWord := unicode.IsLetter(r) || unicode.IsDigit(r)
+		if isWord && start < 0 {
+			start = i
+		}
+		if !isWord && start >= 0 {
+			out = append(out, token{term: strings.ToLower(text[start:i]), start: start, end: i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		out = append(out, token{term: strings.ToLower(text[start:]), start: start, end: len(text)})
+	}
+	return out
+}
+
+func tokenize(text string) []string {
+	toks := tokens(text)
+	terms := make([]string, len(toks))
+	for i, t := range toks {
+		terms[i] = t.term
+	}
+	return terms
+}
+
+// highlight wraps the matched words of text in <mark></mark>; false when nothing matched
+func highlight(text string, matched map[string]bool) (string, bool) {
+	var b strings.Builder
+	last := 0
+	found := false
+	for _, t := range tokens(text) {
+		if !matched[t.term] {
+			continue
+		}
+		found = true
+		b.WriteString(html.EscapeString(text[last:t.start]))
+		b.WriteString("<mark>")
+		b.WriteString(html.EscapeString(text[t.start:t.end]))
+		b.WriteString("</mark>")
+		last = t.end
+	}
+	if !found {
+		return "", false
+	}
+	b.WriteString(html.EscapeString(text[last:]))
+	return b.String(), true
+}
+
+// allowedEdits is how many typos a fuzzy match tolerates: none for short words, one up to 6 letters, then two
+func allowedEdits(term string) int {
+	switch n := utf8.RuneCountInString(term); {
+	case n <= 3:
+		return 0
+	case n <= 6:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// withinEdits reports whether the Levenshtein distance between a and b is at most max
+func withinEdits(a, b string, max int) bool {
+	ra, rb := []rune(a), []rune(b)
+	if diff := len(ra) - len(rb); diff > max || -diff > max {
+		return false
+	}
+
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		rowMin := curr[0]
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+			rowMin = min(rowMin, curr[j])
+		}
+		if rowMin > max {
+			return false
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)] <= max
+}
@@ -0,0 +1,66 @@
+package utils
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Double-submit CSRF protection: the token is set in a cookie readable by the frontend
+// and must be echoed in the header on unsafe requests authenticated with a cookie
+const (
+	CSRFCookie = "csrf_token"
+	CSRFHeader = "X-CSRF-Token"
+)
+
+// CookieSameSite reads COOKIE_SAMESITE: lax (default), strict or none
+func CookieSameSite() http.SameSite {
+	switch strings.ToLower(os.Getenv("COOKIE_SAMESITE")) {
+	case "strict":
+		return http.SameSiteStrictMode
+	case "none":
+		return http.SameSiteNoneMode
+	default:
+		return http.SameSiteLaxMode
+	}
+}
+
+// CookieSecure reads COOKIE_SECURE, defaulting to true when ENV=production.
+// Browsers drop SameSite=None cookies that are not Secure, so None always forces it.
+func CookieSecure() bool {
+	if CookieSameSite() == http.SameSiteNoneMode {
+		return true
+	}
+	switch strings.ToLower(os.Getenv("COOKIE_SECURE")) {
+	case "true", "1", "yes":
+		return true
+	case "false", "0", "no":
+		return false
+	}
+	return os.Getenv("ENV") == "production"
+}
+
+// SetCookie sets a cookie with the configured SameSite, Secure and COOKIE_DOMAIN; maxAge < 0 deletes it
+func SetCookie(c *gin.Context, name, value string, maxAge int, path string, httpOnly bool) {
+	c.SetSameSite(CookieSameSite())
+	c.SetCookie(name, value, maxAge, path, os.Getenv("COOKIE_DOMAIN"), CookieSecure(), httpOnly)
+}
+
+// ValidCSRF reports whether a request carries a matching CSRF header and cookie.
+// Safe methods (GET, HEAD, OPTIONS) never need one.
+func ValidCSRF(c *gin.Context) bool {
+	switch c.Request.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+
+	cookie, err := c.Cookie(CSRFCookie)
+	header := c.GetHeader(CSRFHeader)
+	if err != nil || cookie == "" || header == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(cookie), []byte(header)) == 1
+}
@@ -0,0 +1,46 @@
+package utils
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestValidCSRF(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		cookie string
+		header string
+		want   bool
+	}{
+		{"safe method without token", http.MethodGet, "", "", true},
+		{"head without token", http.MethodHead, "", "", true},
+		{"options without token", http.MethodOptions, "", "", true},
+		{"matching token", http.MethodPost, "abc123", "abc123", true},
+		{"matching token on delete", http.MethodDelete, "abc123", "abc123", true},
+		{"no cookie or header", http.MethodPost, "", "", false},
+		{"header only", http.MethodPost, "", "abc123", false},
+		{"cookie only", http.MethodPut, "abc123", "", false},
+		{"different token", http.MethodPatch, "abc123", "abc124", false},
+		{"prefix of the token", http.MethodPost, "abc123", "abc", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(tt.method, "/", nil)
+			if tt.cookie != "" {
+				c.Request.AddCookie(&http.Cookie{Name: CSRFCookie, Value: tt.cookie})
+			}
+			if tt.header != "" {
+				c.Request.Header.Set(CSRFHeader, tt.header)
+			}
+			if got := ValidCSRF(c); got != tt.want {
+				t.Errorf("ValidCSRF = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
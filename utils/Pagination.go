@@ -0,0 +1,125 @@
+package utils
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+// Pagination holds the page/sort parameters parsed from the query string
+type Pagination struct {
+	Page     int
+	PageSize int
+	Sort     string // column name, already checked against the whitelist
+	Desc     bool
+}
+
+// PageInfo is the envelope returned next to the items of a paginated list
+type PageInfo struct {
+	Page       int     `json:"page"`
+	PageSize   int     `json:"page_size"`
+	Total      int64   `json:"total"`
+	TotalPages int     `json:"total_pages"`
+	Next       *string `json:"next"`
+	Prev       *string `json:"prev"`
+}
+
+// ParsePagination reads ?page=, ?page_size=, ?sort= and ?order= from the request.
+// sortFields maps the public sort name to its column; "-name" is accepted as a shortcut for order=desc.
+// Returns an error message when the sort field is not whitelisted.
+func ParsePagination(c *gin.Context, sortFields map[string]string, defaultSort string) (*Pagination, string) {
+	p := &Pagination{Page: 1, PageSize: DefaultPageSize}
+
+	if page, err := strconv.Atoi(c.Query("page")); err == nil && page > 0 {
+		p.Page = page
+	}
+	if size, err := strconv.Atoi(c.Query("page_size")); err == nil && size > 0 {
+		p.PageSize = size
+	}
+	if p.PageSize > MaxPageSize {
+		p.PageSize = MaxPageSize
+	}
+
+	sortName := c.DefaultQuery("sort", defaultSort)
+	if strings.HasPrefix(sortName, "-") {
+		p.Desc = true
+		sortName = sortName[1:]
+	}
+	column, ok := sortFields[sortName]
+	if !ok {
+		names := make([]string, 0, len(sortFields))
+		for name := range sortFields {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return nil, "Invalid sort field, allowed: " + strings.Join(names, ", ")
+	}
+	p.Sort = column
+
+	switch strings.ToLower(c.Query("order")) {
+	case "":
+	case "asc":
+		p.Desc = false
+	case "desc":
+		p.Desc = true
+	default:
+		return nil, "Invalid order, allowed: asc, desc"
+	}
+
+	return p, ""
+}
+
+// Apply adds ORDER BY / LIMIT / OFFSET to the query. The primary key is used as a tie breaker
+// so pages stay stable when many rows share the same sort value.
+func (p *Pagination) Apply(query *gorm.DB, table string) *gorm.DB {
+	direction := " ASC"
+	if p.Desc {
+		direction = " DESC"
+	}
+	query = query.Order(table + "." + p.Sort + direction)
+	if p.Sort != "id" {
+		query = query.Order(table + ".id" + direction)
+	}
+	return query.Limit(p.PageSize).Offset((p.Page - 1) * p.PageSize)
+}
+
+// Info builds the response envelope with next/prev links that keep every other query parameter
+func (p *Pagination) Info(c *gin.Context, total int64) PageInfo {
+	totalPages := int((total + int64(p.PageSize) - 1) / int64(p.PageSize))
+	info := PageInfo{
+		Page:       p.Page,
+		PageSize:   p.PageSize,
+		Total:      total,
+		TotalPages: totalPages,
+	}
+
+	if p.Page < totalPages {
+		link := pageLink(c, p.Page+1)
+		info.Next = &link
+	}
+	if p.Page > 1 && totalPages > 0 {
+		prev := p.Page - 1
+		if prev > totalPages {
+			prev = totalPages
+		}
+		link := pageLink(c, prev)
+		info.Prev = &link
+	}
+	return info
+}
+
+func pageLink(c *gin.Context, page int) string {
+	u := *c.Request.URL
+	q := u.Query()
+	q.Set("page", strconv.Itoa(page))
+	u.RawQuery = q.Encode()
+	return u.RequestURI()
+}
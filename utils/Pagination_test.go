@@ -0,0 +1,89 @@
+package utils
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+var testSortFields = map[string]string{"title": "title", "year": "year_published", "id": "id"}
+
+func paginationContext(query string) *gin.Context {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodGet, "/novels?"+query, nil)
+	return c
+}
+
+func TestParsePaginationBounds(t *testing.T) {
+	cases := []struct {
+		query          string
+		page, pageSize int
+	}{
+		{"", 1, DefaultPageSize},
+		{"page=3&page_size=5", 3, 5},
+		{"page=0&page_size=0", 1, DefaultPageSize},
+		{"page=-2&page_size=-1", 1, DefaultPageSize},
+		{"page=abc&page_size=xyz", 1, DefaultPageSize},
+		{"page_size=1000", 1, MaxPageSize},
+	}
+	for _, tc := range cases {
+		p, errMsg := ParsePagination(paginationContext(tc.query), testSortFields, "id")
+		if errMsg != "" {
+			t.Fatalf("%q: %s", tc.query, errMsg)
+		}
+		if p.Page != tc.page || p.PageSize != tc.pageSize {
+			t.Errorf("%q: got page %d size %d, want %d %d", tc.query, p.Page, p.PageSize, tc.page, tc.pageSize)
+		}
+	}
+}
+
+func TestParsePaginationSort(t *testing.T) {
+	p, _ := ParsePagination(paginationContext("sort=-year"), testSortFields, "id")
+	if p.Sort != "year_published" || !p.Desc {
+		t.Errorf("-year: got %s desc=%v, want year_published desc", p.Sort, p.Desc)
+	}
+	p, _ = ParsePagination(paginationContext("sort=-year&order=asc"), testSortFields, "id")
+	if p.Desc {
+		t.Error("order=asc did not override the - prefix")
+	}
+
+	// Unknown columns never reach ORDER BY
+	for _, query := range []string{"sort=password", "sort=title%3BDROP%20TABLE%20novels", "order=sideways"} {
+		if _, errMsg := ParsePagination(paginationContext(query), testSortFields, "id"); errMsg == "" {
+			t.Errorf("%q accepted", query)
+		}
+	}
+}
+
+func TestPaginationInfoLinks(t *testing.T) {
+	c := paginationContext("q=dune&page=2&page_size=10")
+	p, _ := ParsePagination(c, testSortFields, "id")
+
+	info := p.Info(c, 25)
+	if info.TotalPages != 3 || info.Total != 25 {
+		t.Errorf("got %d pages of %d, want 3 of 25", info.TotalPages, info.Total)
+	}
+	if info.Next == nil || *info.Next != "/novels?page=3&page_size=10&q=dune" {
+		t.Errorf("next link %v", info.Next)
+	}
+	if info.Prev == nil || *info.Prev != "/novels?page=1&page_size=10&q=dune" {
+		t.Errorf("prev link %v", info.Prev)
+	}
+
+	// Past the last page: no next, prev points at the last real page
+	c = paginationContext("page=9&page_size=10")
+	p, _ = ParsePagination(c, testSortFields, "id")
+	info = p.Info(c, 25)
+	if info.Next != nil || info.Prev == nil || *info.Prev != "/novels?page=3&page_size=10" {
+		t.Errorf("past the end: next %v prev %v", info.Next, info.Prev)
+	}
+
+	// Empty list
+	c = paginationContext("")
+	p, _ = ParsePagination(c, testSortFields, "id")
+	if info = p.Info(c, 0); info.TotalPages != 0 || info.Next != nil || info.Prev != nil {
+		t.Errorf("empty list: %+v", info)
+	}
+}
@@ -0,0 +1,70 @@
+package utils
+
+import (
+	"os"
+	"strings"
+
+	"github.com/Algoritma-dan-Pemrograman-ITS/Framework-Programming-GIN-GORM/models"
+	"github.com/gin-gonic/gin"
+)
+
+// Values of EMAIL_VERIFICATION_POLICY
+const (
+	EmailVerificationNone    = "none"    // unverified users can do everything
+	EmailVerificationReviews = "reviews" // unverified users can log in but not post reviews (default)
+	EmailVerificationLogin   = "login"   // unverified users cannot log in at all
+)
+
+// EmailVerificationPolicy reads EMAIL_VERIFICATION_POLICY, defaulting to "reviews"
+func EmailVerificationPolicy() string {
+	switch policy := strings.ToLower(os.Getenv("EMAIL_VERIFICATION_POLICY")); policy {
+	case EmailVerificationNone, EmailVerificationLogin:
+		return policy
+	default:
+		return EmailVerificationReviews
+	}
+}
+
+// AdminTwoFactorRequired reads REQUIRE_ADMIN_2FA; when true users with the admin role must enable
+// TOTP before using any route guarded by a permission
+func AdminTwoFactorRequired() bool {
+	switch strings.ToLower(os.Getenv("REQUIRE_ADMIN_2FA")) {
+	case "1", "true", "yes":
+		return true
+	}
+	return false
+}
+
+// CheckPermission returns why the request may not use permission, or "" when it may: the user's role
+// must grant it (see models.RolePermissions), a scoped API key must list it, and admins need 2FA when
+// REQUIRE_ADMIN_2FA is on. Controllers call it for checks that depend on the record, like moderating
+// someone else's review.
+func CheckPermission(c *gin.Context, user models.User, permission string) string {
+	if !user.Can(permission) {
+		return "Access forbidden: missing permission " + permission
+	}
+
+	// A scoped API key only carries the permissions it lists
+	if k, exists := c.Get("api_key"); exists {
+		if key, ok := k.(models.APIKey); ok && !key.Allows(permission) {
+			return "API key lacks the " + permission + " scope"
+		}
+	}
+
+	if user.Role == models.RoleAdmin && AdminTwoFactorRequired() && !user.TOTPEnabled {
+		return "Admin accounts must enable two-factor authentication (/2fa/setup)"
+	}
+	return ""
+}
+
+// TrustedProxies reads TRUSTED_PROXIES, a comma separated list of proxy IPs or CIDRs whose
+// X-Forwarded-For header is believed. Empty (default) trusts none and uses the connection's address.
+func TrustedProxies() []string {
+	var proxies []string
+	for _, proxy := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			proxies = append(proxies, proxy)
+		}
+	}
+	return proxies
+}
@@ -0,0 +1,44 @@
+package utils
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Algoritma-dan-Pemrograman-ITS/Framework-Programming-GIN-GORM/models"
+	"github.com/gin-gonic/gin"
+)
+
+func TestCheckPermissionRole(t *testing.T) {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+
+	moderator := models.User{Role: models.RoleModerator}
+	if errMsg := CheckPermission(c, moderator, models.PermReviewModerate); errMsg != "" {
+		t.Errorf("moderator denied review:moderate: %s", errMsg)
+	}
+	if CheckPermission(c, moderator, models.PermUserManage) == "" {
+		t.Error("moderator allowed user:manage")
+	}
+	if CheckPermission(c, models.User{Role: models.RoleUser}, models.PermReviewModerate) == "" {
+		t.Error("regular user allowed review:moderate")
+	}
+}
+
+func TestCheckPermissionAdminTwoFactor(t *testing.T) {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	admin := models.User{Role: models.RoleAdmin}
+
+	t.Setenv("REQUIRE_ADMIN_2FA", "true")
+	if CheckPermission(c, admin, models.PermReviewModerate) == "" {
+		t.Error("admin without 2FA allowed review:moderate while REQUIRE_ADMIN_2FA is on")
+	}
+
+	admin.TOTPEnabled = true
+	if errMsg := CheckPermission(c, admin, models.PermReviewModerate); errMsg != "" {
+		t.Errorf("admin with 2FA denied: %s", errMsg)
+	}
+
+	// The flag is about admins only
+	if errMsg := CheckPermission(c, models.User{Role: models.RoleModerator}, models.PermReviewModerate); errMsg != "" {
+		t.Errorf("moderator without 2FA denied: %s", errMsg)
+	}
+}
@@ -0,0 +1,18 @@
+package utils
+
+import "strings"
+
+// likeEscape is used instead of backslash because MySQL, PostgreSQL and SQLite disagree on the default
+const likeEscape = "!"
+
+// ContainsClause returns a case-insensitive "column contains ?" condition that behaves the same on every
+// supported database (PostgreSQL LIKE is case-sensitive, MySQL/SQLite are not). Use with ContainsPattern.
+func ContainsClause(column string) string {
+	return "LOWER(" + column + ") LIKE ? ESCAPE '" + likeEscape + "'"
+}
+
+// ContainsPattern lowercases the search term and escapes LIKE wildcards so "%" and "_" match literally
+func ContainsPattern(term string) string {
+	replacer := strings.NewReplacer(likeEscape, likeEscape+likeEscape, "%", likeEscape+"%", "_", likeEscape+"_")
+	return "%" + replacer.Replace(strings.ToLower(term)) + "%"
+}
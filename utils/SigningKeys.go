@@ -0,0 +1,220 @@
+package utils
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// signingKey is one key of the set; kid is the file name without ".pem"
+type signingKey struct {
+	kid     string
+	method  jwt.SigningMethod
+	private crypto.Signer
+}
+
+// keySet holds the asymmetric keys loaded from JWT_KEYS_DIR. When no directory is configured
+// tokens keep using HS256 with JWT_SECRET.
+type keySet struct {
+	active *signingKey
+	byKID  map[string]*signingKey
+}
+
+// Values of the "typ" header. Purpose tokens are signed with the same keys as access tokens, so
+// a verifier trusting the JWKS has to check typ to tell them apart.
+const (
+	accessTokenType  = "at+jwt" // RFC 9068
+	purposeTokenType = "purpose+jwt"
+)
+
+var (
+	keys     *keySet
+	keysOnce sync.Once
+	keysErr  error
+)
+
+// LoadSigningKeys reads every PEM private key (PKCS#8 RSA/Ed25519 or PKCS#1 RSA) in JWT_KEYS_DIR.
+// The key named by JWT_ACTIVE_KID signs new tokens (default: the last file name in sort order, so
+// date-named files like "2026-10.pem" rotate naturally). Every other key in the directory is still
+// accepted for verification, which is the rotation window: remove the old file once it is over.
+func LoadSigningKeys() error {
+	keysOnce.Do(func() {
+		keys, keysErr = loadKeySet(os.Getenv("JWT_KEYS_DIR"), os.Getenv("JWT_ACTIVE_KID"))
+	})
+	return keysErr
+}
+
+func currentKeySet() *keySet {
+	LoadSigningKeys()
+	return keys
+}
+
+func loadKeySet(dir, activeKID string) (*keySet, error) {
+	if dir == "" {
+		return nil, nil
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+
+	set := &keySet{byKID: make(map[string]*signingKey)}
+	for _, file := range files {
+		kid := strings.TrimSuffix(filepath.Base(file), ".pem")
+		key, err := readPrivateKey(file)
+		if err != nil {
+			return nil, fmt.Errorf("signing key %s: %w", file, err)
+		}
+		key.kid = kid
+		set.byKID[kid] = key
+		set.active = key
+	}
+
+	if len(set.byKID) == 0 {
+		return nil, fmt.Errorf("no *.pem keys found in JWT_KEYS_DIR %s", dir)
+	}
+	if activeKID != "" {
+		key, ok := set.byKID[activeKID]
+		if !ok {
+			return nil, fmt.Errorf("JWT_ACTIVE_KID %q not found in %s", activeKID, dir)
+		}
+		set.active = key
+	}
+	return set, nil
+}
+
+func readPrivateKey(file string) (*signingKey, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("not a PEM file")
+	}
+
+	var parsed interface{}
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	switch k := parsed.(type) {
+	case *rsa.PrivateKey:
+		return &signingKey{method: jwt.SigningMethodRS256, private: k}, nil
+	case ed25519.PrivateKey:
+		return &signingKey{method: jwt.SigningMethodEdDSA, private: k}, nil
+	default:
+		return nil, errors.New("only RSA and Ed25519 keys are supported")
+	}
+}
+
+// signToken signs claims with the active key (adding its kid), or HS256 + JWT_SECRET when no key set is configured.
+// typ goes in the header, see accessTokenType.
+func signToken(claims jwt.Claims, typ string) (string, error) {
+	set := currentKeySet()
+	if set == nil {
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+		token.Header["typ"] = typ
+		return token.SignedString([]byte(os.Getenv("JWT_SECRET")))
+	}
+
+	token := jwt.NewWithClaims(set.active.method, claims)
+	token.Header["kid"] = set.active.kid
+	token.Header["typ"] = typ
+	return token.SignedString(set.active.private)
+}
+
+// parseToken verifies the signature and expiry of a token and checks its typ header
+func parseToken(tokenString, typ string) (jwt.MapClaims, error) {
+	token, err := jwt.Parse(tokenString, verificationKey)
+	if err != nil || !token.Valid {
+		return nil, errors.New("invalid token")
+	}
+	if token.Header["typ"] != typ {
+		return nil, errors.New("invalid token type")
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, errors.New("invalid token claims")
+	}
+	return claims, nil
+}
+
+// verificationKey is the jwt.Keyfunc used by every parser: it picks the key by kid and
+// refuses tokens whose algorithm does not match that key
+func verificationKey(t *jwt.Token) (interface{}, error) {
+	set := currentKeySet()
+	if set == nil {
+		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, jwt.ErrSignatureInvalid
+		}
+		return []byte(os.Getenv("JWT_SECRET")), nil
+	}
+
+	kid, _ := t.Header["kid"].(string)
+	key, ok := set.byKID[kid]
+	if !ok {
+		return nil, errors.New("unknown signing key")
+	}
+	if t.Method.Alg() != key.method.Alg() {
+		return nil, jwt.ErrSignatureInvalid
+	}
+	return key.private.Public(), nil
+}
+
+// JWKS returns the public keys as a JSON Web Key Set (RFC 7517), empty when HS256 is used
+func JWKS() map[string]interface{} {
+	result := []map[string]interface{}{}
+
+	set := currentKeySet()
+	if set != nil {
+		kids := make([]string, 0, len(set.byKID))
+		for kid := range set.byKID {
+			kids = append(kids, kid)
+		}
+		sort.Strings(kids)
+
+		for _, kid := range kids {
+			key := set.byKID[kid]
+			jwk := map[string]interface{}{"kid": kid, "use": "sig", "alg": key.method.Alg()}
+			switch pub := key.private.Public().(type) {
+			case *rsa.PublicKey:
+				jwk["kty"] = "RSA"
+				jwk["n"] = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+				jwk["e"] = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+			case ed25519.PublicKey:
+				jwk["kty"] = "OKP"
+				jwk["crv"] = "Ed25519"
+				jwk["x"] = base64.RawURLEncoding.EncodeToString(pub)
+			}
+			result = append(result, jwk)
+		}
+	}
+
+	return map[string]interface{}{"keys": result}
+}
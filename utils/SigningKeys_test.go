@@ -0,0 +1,199 @@
+package utils
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// writeKeys writes an RSA key as PKCS#1 ("2026-01.pem") and an Ed25519 key as PKCS#8 ("2026-02.pem")
+func writeKeys(t *testing.T) (string, *rsa.PrivateKey, ed25519.PrivateKey) {
+	t.Helper()
+	dir := t.TempDir()
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkcs8, err := x509.MarshalPKCS8PrivateKey(edKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files := map[string]*pem.Block{
+		"2026-01.pem": {Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)},
+		"2026-02.pem": {Type: "PRIVATE KEY", Bytes: pkcs8},
+	}
+	for name, block := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), pem.EncodeToMemory(block), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir, rsaKey, edKey
+}
+
+// useKeySet makes set the loaded key set for the rest of the test
+func useKeySet(t *testing.T, set *keySet) {
+	t.Helper()
+	LoadSigningKeys()
+	previous := keys
+	keys = set
+	t.Cleanup(func() { keys = previous })
+}
+
+func TestLoadKeySet(t *testing.T) {
+	dir, _, _ := writeKeys(t)
+
+	set, err := loadKeySet(dir, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(set.byKID) != 2 {
+		t.Fatalf("got %d keys, want 2", len(set.byKID))
+	}
+	if set.active.kid != "2026-02" || set.active.method != jwt.SigningMethodEdDSA {
+		t.Errorf("default active key %s (%s), want the last file 2026-02 (EdDSA)", set.active.kid, set.active.method.Alg())
+	}
+
+	set, err = loadKeySet(dir, "2026-01")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if set.active.kid != "2026-01" || set.active.method != jwt.SigningMethodRS256 {
+		t.Errorf("JWT_ACTIVE_KID picked %s (%s), want 2026-01 (RS256)", set.active.kid, set.active.method.Alg())
+	}
+
+	if _, err := loadKeySet(dir, "2025-12"); err == nil {
+		t.Error("unknown JWT_ACTIVE_KID accepted")
+	}
+	if _, err := loadKeySet(t.TempDir(), ""); err == nil {
+		t.Error("directory without keys accepted")
+	}
+	if set, err := loadKeySet("", ""); set != nil || err != nil {
+		t.Errorf("no JWT_KEYS_DIR: got %v, %v, want HS256 (nil, nil)", set, err)
+	}
+
+	os.WriteFile(filepath.Join(dir, "broken.pem"), []byte("not a key"), 0600)
+	if _, err := loadKeySet(dir, ""); err == nil {
+		t.Error("directory with a broken key accepted")
+	}
+}
+
+func TestSignWithKeySetAndRotate(t *testing.T) {
+	dir, _, _ := writeKeys(t)
+	set, err := loadKeySet(dir, "2026-01")
+	if err != nil {
+		t.Fatal(err)
+	}
+	useKeySet(t, set)
+
+	old, err := GenerateAccessToken(42, "family-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	header, _, _ := new(jwt.Parser).ParseUnverified(old, jwt.MapClaims{})
+	if header.Header["kid"] != "2026-01" || header.Header["alg"] != "RS256" || header.Header["typ"] != accessTokenType {
+		t.Errorf("got header %v, want kid 2026-01, RS256 and typ %s", header.Header, accessTokenType)
+	}
+
+	// After switching the active key, tokens signed with the previous one stay valid
+	set.active = set.byKID["2026-02"]
+	current, err := GenerateAccessToken(42, "family-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, token := range []string{old, current} {
+		if _, err := ParseAccessToken(token); err != nil {
+			t.Errorf("token rejected after rotation: %v", err)
+		}
+	}
+}
+
+func TestVerificationKeyRejectsAlgMismatch(t *testing.T) {
+	dir, rsaKey, edKey := writeKeys(t)
+	set, err := loadKeySet(dir, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	useKeySet(t, set)
+
+	claims := jwt.MapClaims{"sub": "42", "exp": time.Now().Add(time.Hour).Unix()}
+	sign := func(method jwt.SigningMethod, kid string, key interface{}) string {
+		token := jwt.NewWithClaims(method, claims)
+		token.Header["kid"] = kid
+		token.Header["typ"] = accessTokenType
+		signed, err := token.SignedString(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return signed
+	}
+
+	forged := map[string]string{
+		// HS256 keyed with the published RSA public key
+		"HS256 with a public key":  sign(jwt.SigningMethodHS256, "2026-01", x509.MarshalPKCS1PublicKey(&rsaKey.PublicKey)),
+		"EdDSA under an RSA kid":   sign(jwt.SigningMethodEdDSA, "2026-01", edKey),
+		"RS256 under an EdDSA kid": sign(jwt.SigningMethodRS256, "2026-02", rsaKey),
+		"unknown kid":              sign(jwt.SigningMethodEdDSA, "2025-12", edKey),
+	}
+	for name, token := range forged {
+		if _, err := ParseAccessToken(token); err == nil {
+			t.Errorf("%s: token accepted", name)
+		}
+	}
+
+	if _, err := ParseAccessToken(sign(jwt.SigningMethodRS256, "2026-01", rsaKey)); err != nil {
+		t.Errorf("matching kid and alg rejected: %v", err)
+	}
+}
+
+func TestJWKS(t *testing.T) {
+	dir, rsaKey, edKey := writeKeys(t)
+	set, err := loadKeySet(dir, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	useKeySet(t, set)
+
+	jwks := JWKS()["keys"].([]map[string]interface{})
+	if len(jwks) != 2 {
+		t.Fatalf("got %d keys, want 2", len(jwks))
+	}
+
+	rsaJWK, edJWK := jwks[0], jwks[1]
+	if rsaJWK["kid"] != "2026-01" || rsaJWK["kty"] != "RSA" || rsaJWK["alg"] != "RS256" ||
+		rsaJWK["n"] != base64.RawURLEncoding.EncodeToString(rsaKey.N.Bytes()) || rsaJWK["e"] != "AQAB" {
+		t.Errorf("unexpected RSA key %v", rsaJWK)
+	}
+	if edJWK["kid"] != "2026-02" || edJWK["kty"] != "OKP" || edJWK["crv"] != "Ed25519" || edJWK["alg"] != "EdDSA" ||
+		edJWK["x"] != base64.RawURLEncoding.EncodeToString(edKey.Public().(ed25519.PublicKey)) {
+		t.Errorf("unexpected Ed25519 key %v", edJWK)
+	}
+	for _, jwk := range jwks {
+		for _, field := range []string{"d", "p", "q", "dp", "dq", "qi"} {
+			if _, ok := jwk[field]; ok {
+				t.Errorf("private field %q published for %v", field, jwk["kid"])
+			}
+		}
+	}
+}
+
+func TestJWKSEmptyWithHS256(t *testing.T) {
+	useKeySet(t, nil)
+	if jwks := JWKS()["keys"].([]map[string]interface{}); len(jwks) != 0 {
+		t.Errorf("got %d keys with HS256, want none", len(jwks))
+	}
+}
@@ -0,0 +1,51 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+// RFC 6238 appendix B vectors (SHA1), truncated to our 6 digits
+func TestTOTPCode(t *testing.T) {
+	key := []byte("12345678901234567890")
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+	}
+	for _, tt := range tests {
+		if got := totpCode(key, tt.unix/totpPeriod); got != tt.want {
+			t.Errorf("totpCode at %d = %s, want %s", tt.unix, got, tt.want)
+		}
+	}
+}
+
+func TestValidateTOTP(t *testing.T) {
+	secret, err := GenerateTOTPSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, _ := totpEncoding.DecodeString(secret)
+	current := time.Now().Unix() / totpPeriod
+
+	step, ok := ValidateTOTP(secret, totpCode(key, current), 0)
+	if !ok || step != current {
+		t.Fatalf("current code rejected (step %d, ok %v)", step, ok)
+	}
+	if _, ok := ValidateTOTP(secret, totpCode(key, current), step); ok {
+		t.Error("same code accepted twice")
+	}
+	if _, ok := ValidateTOTP(secret, " "+totpCode(key, current-1)+" ", 0); !ok {
+		t.Error("code from the previous step rejected despite the allowed skew")
+	}
+	if _, ok := ValidateTOTP(secret, totpCode(key, current-3), 0); ok {
+		t.Error("code three steps old accepted")
+	}
+	if _, ok := ValidateTOTP(secret, "12345", 0); ok {
+		t.Error("short code accepted")
+	}
+}
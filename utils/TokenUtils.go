@@ -0,0 +1,139 @@
+package utils
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// Default lifetimes, can be overridden with ACCESS_TOKEN_TTL / REFRESH_TOKEN_TTL (e.g. "15m", "720h")
+const (
+	DefaultAccessTokenTTL  = 15 * time.Minute
+	DefaultRefreshTokenTTL = 30 * 24 * time.Hour
+)
+
+// AccessClaims is the data we read back from a verified access token
+type AccessClaims struct {
+	UserID   uint
+	FamilyID string
+}
+
+func AccessTokenTTL() time.Duration {
+	return DurationFromEnv("ACCESS_TOKEN_TTL", DefaultAccessTokenTTL)
+}
+
+func RefreshTokenTTL() time.Duration {
+	return DurationFromEnv("REFRESH_TOKEN_TTL", DefaultRefreshTokenTTL)
+}
+
+// DurationFromEnv parses a time.Duration env variable, using fallback when it is missing or invalid
+func DurationFromEnv(key string, fallback time.Duration) time.Duration {
+	if v := os.Getenv(key); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d > 0 {
+			return d
+		}
+	}
+	return fallback
+}
+
+// GenerateAccessToken signs a short-lived token for the user (see signToken for the algorithm).
+// familyID ties the token to the refresh token family it was issued with, so logout can revoke it.
+func GenerateAccessToken(userID uint, familyID string) (string, error) {
+	return signToken(jwt.MapClaims{
+		"sub": strconv.FormatUint(uint64(userID), 10),
+		"fid": familyID,
+		"exp": time.Now().Add(AccessTokenTTL()).Unix(),
+	}, accessTokenType)
+}
+
+// ParseAccessToken verifies the signature and expiry and returns the claims we care about
+func ParseAccessToken(tokenString string) (*AccessClaims, error) {
+	claims, err := parseToken(tokenString, accessTokenType)
+	if err != nil {
+		return nil, err
+	}
+
+	// Purpose tokens (email verification, pending 2FA, ...) share the key but are not access tokens
+	if _, hasPurpose := claims["pur"]; hasPurpose {
+		return nil, errors.New("invalid token type")
+	}
+
+	sub, ok := claims["sub"].(string)
+	if !ok {
+		return nil, errors.New("invalid token subject")
+	}
+	id, err := strconv.ParseUint(sub, 10, 64)
+	if err != nil {
+		return nil, errors.New("invalid token subject")
+	}
+
+	// fid is optional so tokens minted before refresh tokens existed still parse
+	familyID, _ := claims["fid"].(string)
+
+	return &AccessClaims{UserID: uint(id), FamilyID: familyID}, nil
+}
+
+// GenerateSignedToken creates a signed, expiring token for a single purpose (e.g. "verify_email").
+// extra claims are added as-is; purpose and exp are always set.
+func GenerateSignedToken(purpose string, userID uint, extra map[string]interface{}, ttl time.Duration) (string, error) {
+	claims := jwt.MapClaims{
+		"sub": strconv.FormatUint(uint64(userID), 10),
+		"pur": purpose,
+		"exp": time.Now().Add(ttl).Unix(),
+	}
+	for k, v := range extra {
+		claims[k] = v
+	}
+
+	return signToken(claims, purposeTokenType)
+}
+
+// ParseSignedToken verifies a token from GenerateSignedToken and checks its purpose
+func ParseSignedToken(tokenString, purpose string) (jwt.MapClaims, error) {
+	claims, err := parseToken(tokenString, purposeTokenType)
+	if err != nil {
+		return nil, err
+	}
+	if claims["pur"] != purpose {
+		return nil, errors.New("invalid token purpose")
+	}
+	return claims, nil
+}
+
+// AppURL is the frontend base URL used to build links in emails
+func AppURL() string {
+	if v := os.Getenv("APP_URL"); v != "" {
+		return strings.TrimRight(v, "/")
+	}
+	return "http://localhost:3000"
+}
+
+// APIURL is the public base URL of this API, used to build OAuth redirect URIs and links to API endpoints in emails
+func APIURL() string {
+	if v := os.Getenv("API_URL"); v != "" {
+		return strings.TrimRight(v, "/")
+	}
+	return "http://localhost:8001"
+}
+
+// RandomToken returns a URL-safe random string of n bytes (hex encoded)
+func RandomToken(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// HashToken is used to store opaque tokens (refresh tokens, etc.) without keeping the raw value
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,102 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+func TestParseAccessToken(t *testing.T) {
+	t.Setenv("JWT_SECRET", "test-secret")
+
+	token, err := GenerateAccessToken(42, "family-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	claims, err := ParseAccessToken(token)
+	if err != nil {
+		t.Fatalf("valid access token rejected: %v", err)
+	}
+	if claims.UserID != 42 || claims.FamilyID != "family-1" {
+		t.Errorf("got user %d family %q, want 42 %q", claims.UserID, claims.FamilyID, "family-1")
+	}
+}
+
+func TestParseAccessTokenRejectsPurposeTokens(t *testing.T) {
+	t.Setenv("JWT_SECRET", "test-secret")
+
+	// Same key and sub claim as an access token, e.g. the emailed verification link
+	for _, purpose := range []string{"verify_email", "change_email", "mfa_pending", "oidc_state"} {
+		token, err := GenerateSignedToken(purpose, 42, map[string]interface{}{"email": "a@example.com"}, time.Hour)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := ParseAccessToken(token); err == nil {
+			t.Errorf("%s token accepted as an access token", purpose)
+		}
+	}
+}
+
+func TestParseSignedTokenChecksPurpose(t *testing.T) {
+	t.Setenv("JWT_SECRET", "test-secret")
+
+	token, err := GenerateSignedToken("verify_email", 42, nil, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ParseSignedToken(token, "verify_email"); err != nil {
+		t.Errorf("token rejected for its own purpose: %v", err)
+	}
+	if _, err := ParseSignedToken(token, "change_email"); err == nil {
+		t.Error("token accepted for another purpose")
+	}
+
+	access, err := GenerateAccessToken(42, "family-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ParseSignedToken(access, "verify_email"); err == nil {
+		t.Error("access token accepted as a purpose token")
+	}
+}
+
+func TestTokensCarryTheirType(t *testing.T) {
+	t.Setenv("JWT_SECRET", "test-secret")
+
+	access, err := GenerateAccessToken(42, "family-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	purpose, err := GenerateSignedToken("verify_email", 42, nil, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for token, want := range map[string]string{access: accessTokenType, purpose: purposeTokenType} {
+		parsed, _, err := new(jwt.Parser).ParseUnverified(token, jwt.MapClaims{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if parsed.Header["typ"] != want {
+			t.Errorf("got typ %v, want %s", parsed.Header["typ"], want)
+		}
+	}
+
+	// Same key and claims, but an untyped (or plain "JWT") header is neither kind of token
+	untyped := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub": "42",
+		"pur": "verify_email",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	})
+	signed, err := untyped.SignedString([]byte("test-secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ParseAccessToken(signed); err == nil {
+		t.Error("untyped token accepted as an access token")
+	}
+	if _, err := ParseSignedToken(signed, "verify_email"); err == nil {
+		t.Error("untyped token accepted as a purpose token")
+	}
+}